@@ -1,86 +1,137 @@
+// Command pristine processes a directory of styles and generates a Go package for loading them.
+//
+// It is designed to be run from go generate, for example:
+//
+//	//go:generate go run github.com/aledsdavies/pristinecss/cmd/pristine -src ./css -out .
+//
+// When run from go generate the package name defaults to $GOPACKAGE.
+//
+// The minify subcommand writes the smallest equivalent CSS for the given files:
+//
+//	pristine minify -o site.min.css site.css
 package main
 
 import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aledsdavies/pristinecss/generator"
 	"github.com/aledsdavies/pristinecss/processor"
 )
 
-var templateString = `// Code generated by StyloCSS tool; DO NOT EDIT.
-// This file was generated at {{.Timestamp}}.
-package {{.PackageName}}
-
-import (
-    "embed"
-    "context"
-    "github.com/aledsdavies/pristinecss"
-)
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "minify" {
+		if err := runMinify(os.Args[2:]); err != nil {
+			log.Fatalf("Error minifying: %v", err)
+		}
+		return
+	}
 
-//go:embed {{.CSSPaths}}
-var embeddedCss embed.FS
+	source := flag.String("src", ".", "directory containing the style files to process")
+	outputDir := flag.String("out", "./public", "directory where the generated Go file is written")
+	stylesDir := flag.String("styles", "", "directory where processed styles are written (default <out>/styles)")
+	packageName := flag.String("pkg", os.Getenv("GOPACKAGE"), "package name of the generated file (default $GOPACKAGE or the base name of -out)")
+	fileName := flag.String("file", "styles_gen.go", "name of the generated Go file")
+	scoped := flag.Bool("scoped", false, "rewrite class names to names scoped to their file, as in CSS Modules")
+	sourceMaps := flag.String("sourcemap", "none", "source maps to write for processed styles: none, linked, external or inline")
+	verbose := flag.Bool("v", false, "log each processed file")
+	flag.Parse()
 
-var cssFiles = map[string]string{
-{{range $key, $value := .Files}}    "{{$key}}": "{{$value}}",
-{{end}}}
+	if *stylesDir == "" {
+		*stylesDir = filepath.Join(*outputDir, "styles")
+	}
+	if *packageName == "" {
+		*packageName = defaultPackageName(*outputDir)
+	}
 
-var cssClasses = map[string]stylocss.CSSClass{
-{{range $key, $value := .Classes}}    "{{$key}}": { Path: "{{$value.Path}}", Class: "{{$value.Class}}" },
-{{end}}}
+	embedPath, err := embedPath(*outputDir, *stylesDir)
+	if err != nil {
+		log.Fatalf("Invalid styles directory: %v", err)
+	}
 
-var loader = stylocss.NewLoader(cssFiles, cssClasses)
+	sourceMapMode, err := parseSourceMapMode(*sourceMaps)
+	if err != nil {
+		log.Fatalf("Invalid -sourcemap: %v", err)
+	}
 
-func Class(ctx context.Context, className string) (string, error) {
-    return loader.Class(ctx, className)
-}
+	result, err := processor.Process(*source,
+		processor.WithVerbose(*verbose),
+		processor.WithOutputDir(*outputDir),
+		processor.WithStylesDir(*stylesDir),
+		processor.WithScopedClasses(*scoped),
+		processor.WithSourceMaps(sourceMapMode),
+	)
+	if err != nil {
+		log.Fatalf("Error processing styles: %v", err)
+	}
 
-func LoadedFiles(ctx context.Context) []string {
-    return loader.LoadedFiles(ctx)
-}`
+	file, err := os.Create(filepath.Join(*outputDir, *fileName))
+	if err != nil {
+		log.Fatalf("Error creating file: %v", err)
+	}
+	defer file.Close()
 
-func main() {
-	processor.Process("./", processor.WithVerbose(true))
-}
-[]byt
-/*
-func main() {
-	tmpl, err := template.New("cssTemplate").Parse(templateString) // templateString is your template defined earlier
+	err = generator.Generate(file, result,
+		generator.WithPackageName(*packageName),
+		generator.WithEmbedPath(embedPath),
+	)
 	if err != nil {
-		log.Fatalf("Error parsing template: %v", err)
+		log.Fatalf("Error generating loader: %v", err)
 	}
+}
 
-	// Prepare data for the template
-	data := struct {
-        PackageName string
-		CSSPaths string
-		Files    map[string]string
-		Classes  map[string]stylocss.CSSClass
-		Timestamp string
-	}{
-        PackageName: "css",
-		CSSPaths: "styles/reset.css,styles/main.css",
-		Files: map[string]string{
-			"reset.css": "/d41d8cd98f/reset.css",
-			"main.css":  "/d3djskdjifc/main.css",
-		},
-		Classes: map[string]stylocss.CSSClass {
-			"main.button": {Path: "main.css", Class: "button_234mjd"},
-			"main.header": {Path: "main.css", Class: "header_233443"},
-		},
-		Timestamp: time.Now().Format(time.RFC1123),
+// defaultPackageName derives a package name from the output directory.
+func defaultPackageName(outputDir string) string {
+	abs, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "styles"
 	}
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, filepath.Base(abs))
+}
 
-    // Ensure the build directory exists.
-    if err := os.MkdirAll("./build", 0755); err != nil {
-        log.Fatalf("Failed to create build directory: %v", err)
-    }
+// parseSourceMapMode returns the processor source map mode for the -sourcemap flag.
+func parseSourceMapMode(mode string) (processor.SourceMapMode, error) {
+	switch mode {
+	case "none":
+		return processor.NoSourceMap, nil
+	case "linked":
+		return processor.LinkedSourceMap, nil
+	case "external":
+		return processor.ExternalSourceMap, nil
+	case "inline":
+		return processor.InlineSourceMap, nil
+	default:
+		return processor.NoSourceMap, fmt.Errorf("unknown mode %q, expected none, linked, external or inline", mode)
+	}
+}
 
-	file, err := os.Create("build/output.go")
+// embedPath returns the styles directory relative to the output directory, which is the form
+// required by the go:embed directive.
+func embedPath(outputDir, stylesDir string) (string, error) {
+	absOutput, err := filepath.Abs(outputDir)
 	if err != nil {
-		log.Fatalf("Error creating file: %v", err)
+		return "", err
+	}
+	absStyles, err := filepath.Abs(stylesDir)
+	if err != nil {
+		return "", err
 	}
-	defer file.Close()
 
-	err = tmpl.Execute(file, data)
+	rel, err := filepath.Rel(absOutput, absStyles)
 	if err != nil {
-		log.Fatalf("Error executing template: %v", err)
+		return "", err
+	}
+	if rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s must be inside the output directory %s to be embedded", stylesDir, outputDir)
 	}
+	return filepath.ToSlash(rel), nil
 }
-*/
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aledsdavies/pristinecss/pkg/minify"
+)
+
+// runMinify implements the minify subcommand, which minifies each file given as an argument,
+// or stdin when there are none, and writes the concatenated result to stdout or -o.
+func runMinify(args []string) error {
+	flags := flag.NewFlagSet("minify", flag.ExitOnError)
+	output := flags.String("o", "", "file to write the minified CSS to (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: pristine minify [-o output] [files...]\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var out bytes.Buffer
+	if flags.NArg() == 0 {
+		if err := minify.Minify(&out, os.Stdin); err != nil {
+			return fmt.Errorf("stdin: %w", err)
+		}
+	}
+	for _, name := range flags.Args() {
+		if err := minifyFile(&out, name); err != nil {
+			return err
+		}
+	}
+
+	if *output == "" {
+		_, err := io.Copy(os.Stdout, &out)
+		return err
+	}
+	return os.WriteFile(*output, out.Bytes(), 0644)
+}
+
+func minifyFile(w io.Writer, name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := minify.Minify(w, file); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
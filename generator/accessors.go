@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aledsdavies/pristinecss"
+)
+
+// reservedNames are the exported identifiers already declared by the generated package.
+var reservedNames = map[string]bool{
+	"Class":       true,
+	"LoadedFiles": true,
+}
+
+// classGroup is the typed accessor generated for the classes of a single source file.
+type classGroup struct {
+	Name     string
+	TypeName string
+	File     string
+	Classes  []classAccessor
+}
+
+// classAccessor is the method generated for a single class.
+type classAccessor struct {
+	Name  string
+	Key   string
+	Class string
+}
+
+// classGroups groups the classes by the file that defines them and assigns each file and
+// class a unique Go identifier. Groups and classes are sorted so the output is stable.
+func classGroups(classes map[string]pristinecss.CSSClass) []classGroup {
+	byFile := make(map[string][]string)
+	for key, class := range classes {
+		byFile[class.Path] = append(byFile[class.Path], key)
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	groupNames := make(map[string]bool)
+	for name := range reservedNames {
+		groupNames[name] = true
+	}
+
+	groups := make([]classGroup, 0, len(files))
+	for _, file := range files {
+		prefix := strings.TrimSuffix(file, path.Ext(file))
+		name := uniqueIdentifier(exportedIdentifier(prefix), groupNames)
+		group := classGroup{
+			Name:     name,
+			TypeName: unexport(name) + "Group",
+			File:     file,
+		}
+
+		keys := byFile[file]
+		sort.Strings(keys)
+
+		methodNames := make(map[string]bool)
+		for _, key := range keys {
+			class := strings.TrimPrefix(key, prefix+".")
+			group.Classes = append(group.Classes, classAccessor{
+				Name:  uniqueIdentifier(exportedIdentifier(class), methodNames),
+				Key:   key,
+				Class: class,
+			})
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// exportedIdentifier converts a CSS name such as "nav-item" or "components/card" into an
+// exported Go identifier such as "NavItem" or "ComponentsCard".
+func exportedIdentifier(name string) string {
+	var sb strings.Builder
+	upperNext := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		sb.WriteRune(r)
+	}
+
+	ident := sb.String()
+	if ident == "" || !unicode.IsUpper([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+// uniqueIdentifier returns the identifier, suffixed with a number if it is already taken, and
+// marks it as taken.
+func uniqueIdentifier(ident string, taken map[string]bool) string {
+	unique := ident
+	for i := 2; taken[unique]; i++ {
+		unique = ident + strconv.Itoa(i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// unexport lowercases the first letter of an identifier.
+func unexport(ident string) string {
+	runes := []rune(ident)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
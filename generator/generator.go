@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"text/template"
+
+	"github.com/aledsdavies/pristinecss"
+	"github.com/aledsdavies/pristinecss/processor"
+)
+
+var templateString = `// Code generated by pristine; DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"context"
+	"embed"
+
+	"github.com/aledsdavies/pristinecss"
+)
+
+//go:embed {{.EmbedPath}}
+var embeddedCss embed.FS
+
+var cssFiles = map[string]string{
+{{range $key, $value := .Files}}	{{printf "%q" $key}}: {{printf "%q" $value}},
+{{end}}}
+
+var cssClasses = map[string]pristinecss.CSSClass{
+{{range $key, $value := .Classes}}	{{printf "%q" $key}}: {Path: {{printf "%q" $value.Path}}, Class: {{printf "%q" $value.Class}}{{if $value.Composes}}, Composes: []pristinecss.CSSClass{
+{{range $value.Composes}}		{Path: {{printf "%q" .Path}}, Class: {{printf "%q" .Class}}},
+{{end}}	}{{end}}},
+{{end}}}
+
+var loader = pristinecss.NewLoader(cssFiles, cssClasses)
+
+func Class(ctx context.Context, className string) (string, error) {
+	return loader.Class(ctx, className)
+}
+
+func LoadedFiles(ctx context.Context) []string {
+	return loader.LoadedFiles(ctx)
+}
+
+// mustClass retrieves a class that is known to exist because it was generated from the styles.
+func mustClass(ctx context.Context, className string) string {
+	class, err := loader.Class(ctx, className)
+	if err != nil {
+		panic(err)
+	}
+	return class
+}
+{{range .Groups}}
+type {{.TypeName}} struct{}
+
+// {{.Name}} holds the classes defined in {{.File}}.
+var {{.Name}} {{.TypeName}}
+{{$group := .}}{{range .Classes}}
+// {{.Name}} returns the {{.Class}} class from {{$group.File}}.
+func ({{$group.TypeName}}) {{.Name}}(ctx context.Context) string {
+	return mustClass(ctx, {{printf "%q" .Key}})
+}
+{{end}}{{end}}`
+
+var tmpl = template.Must(template.New("cssTemplate").Parse(templateString))
+
+type GeneratorOpt func(*generatorOptions)
+
+type generatorOptions struct {
+	packageName string
+	embedPath   string
+}
+
+// WithPackageName sets the package name of the generated file
+func WithPackageName(packageName string) GeneratorOpt {
+	return func(opts *generatorOptions) {
+		opts.packageName = packageName
+	}
+}
+
+// WithEmbedPath sets the path, relative to the generated file, of the processed styles to embed
+func WithEmbedPath(embedPath string) GeneratorOpt {
+	return func(opts *generatorOptions) {
+		opts.embedPath = embedPath
+	}
+}
+
+type templateData struct {
+	PackageName string
+	EmbedPath   string
+	Files       map[string]string
+	Classes     map[string]pristinecss.CSSClass
+	Groups      []classGroup
+}
+
+// Generate writes the Go source of a loader package for the processed styles.
+// The output is formatted and entries are ordered by key so regenerating unchanged styles
+// produces an identical file.
+//
+// Parameters:
+// - w: The writer the generated source is written to.
+// - result: The output of processor.Process.
+// - opts: Options to configure the generated package.
+//
+// Returns:
+// - An error if the source could not be generated or written.
+func Generate(w io.Writer, result *processor.Result, opts ...GeneratorOpt) error {
+	options := &generatorOptions{
+		packageName: "styles",
+		embedPath:   "styles",
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, templateData{
+		PackageName: options.packageName,
+		EmbedPath:   options.embedPath,
+		Files:       result.Files,
+		Classes:     result.Classes,
+		Groups:      classGroups(result.Classes),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated source: %w", err)
+	}
+
+	_, err = w.Write(src)
+	return err
+}
@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss"
+	"github.com/aledsdavies/pristinecss/processor"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerate(t *testing.T) {
+	result := &processor.Result{
+		Files: map[string]string{
+			"reset.css": "/d41d8cd98f/reset.css",
+			"main.css":  "/d3djskdjif/main.css",
+		},
+		Classes: map[string]pristinecss.CSSClass{
+			"main.header": {Path: "main.css", Class: "header"},
+			"main.button": {Path: "main.css", Class: "button", Composes: []pristinecss.CSSClass{
+				{Path: "reset.css", Class: "base"},
+				{Class: "clearfix"},
+			}},
+		},
+	}
+
+	expected := `// Code generated by pristine; DO NOT EDIT.
+
+package css
+
+import (
+	"context"
+	"embed"
+
+	"github.com/aledsdavies/pristinecss"
+)
+
+//go:embed public/styles
+var embeddedCss embed.FS
+
+var cssFiles = map[string]string{
+	"main.css":  "/d3djskdjif/main.css",
+	"reset.css": "/d41d8cd98f/reset.css",
+}
+
+var cssClasses = map[string]pristinecss.CSSClass{
+	"main.button": {Path: "main.css", Class: "button", Composes: []pristinecss.CSSClass{
+		{Path: "reset.css", Class: "base"},
+		{Path: "", Class: "clearfix"},
+	}},
+	"main.header": {Path: "main.css", Class: "header"},
+}
+
+var loader = pristinecss.NewLoader(cssFiles, cssClasses)
+
+func Class(ctx context.Context, className string) (string, error) {
+	return loader.Class(ctx, className)
+}
+
+func LoadedFiles(ctx context.Context) []string {
+	return loader.LoadedFiles(ctx)
+}
+
+// mustClass retrieves a class that is known to exist because it was generated from the styles.
+func mustClass(ctx context.Context, className string) string {
+	class, err := loader.Class(ctx, className)
+	if err != nil {
+		panic(err)
+	}
+	return class
+}
+
+type mainGroup struct{}
+
+// Main holds the classes defined in main.css.
+var Main mainGroup
+
+// Button returns the button class from main.css.
+func (mainGroup) Button(ctx context.Context) string {
+	return mustClass(ctx, "main.button")
+}
+
+// Header returns the header class from main.css.
+func (mainGroup) Header(ctx context.Context) string {
+	return mustClass(ctx, "main.header")
+}
+`
+
+	var first, second bytes.Buffer
+	opts := []GeneratorOpt{WithPackageName("css"), WithEmbedPath("public/styles")}
+	if err := Generate(&first, result, opts...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := Generate(&second, result, opts...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(expected, first.String()); diff != "" {
+		t.Errorf("Generated source mismatch (-want +got):\n%s", diff)
+	}
+	if first.String() != second.String() {
+		t.Errorf("Expected generated source to be deterministic")
+	}
+}
+
+func TestClassGroups(t *testing.T) {
+	classes := map[string]pristinecss.CSSClass{
+		"components/card.card-title": {Path: "components/card.css", Class: "card-title"},
+		"components/card.card_title": {Path: "components/card.css", Class: "card_title"},
+		"components/card.2xl":        {Path: "components/card.css", Class: "2xl"},
+		"class.active":               {Path: "class.css", Class: "active"},
+	}
+
+	expected := []classGroup{
+		{
+			Name:     "Class2",
+			TypeName: "class2Group",
+			File:     "class.css",
+			Classes: []classAccessor{
+				{Name: "Active", Key: "class.active", Class: "active"},
+			},
+		},
+		{
+			Name:     "ComponentsCard",
+			TypeName: "componentsCardGroup",
+			File:     "components/card.css",
+			Classes: []classAccessor{
+				{Name: "X2xl", Key: "components/card.2xl", Class: "2xl"},
+				{Name: "CardTitle", Key: "components/card.card-title", Class: "card-title"},
+				{Name: "CardTitle2", Key: "components/card.card_title", Class: "card_title"},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, classGroups(classes)); diff != "" {
+		t.Errorf("Class groups mismatch (-want +got):\n%s", diff)
+	}
+}
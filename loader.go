@@ -3,40 +3,45 @@ package pristinecss
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
 type CSSClass struct {
 	Path  string
 	Class string
+	// Composes lists the classes pulled in by composes declarations. Classes composed from global
+	// have no Path.
+	Composes []CSSClass
 }
 
+// CSSLoader resolves class names and versioned file paths. It holds no per-request state so
+// a single loader can be shared by concurrent requests.
 type CSSLoader struct {
-	cssFiles    map[string]string
-	cssClasses  map[string]CSSClass
-	alwaysLoad  []string
-	loadedFiles map[string][]string
+	cssFiles   map[string]string
+	cssClasses map[string]CSSClass
+	alwaysLoad []string
 }
 
 func NewLoader(cssFiles map[string]string, cssClasses map[string]CSSClass) *CSSLoader {
 	return &CSSLoader{
-		cssFiles:    cssFiles,
-		cssClasses:  cssClasses,
-		alwaysLoad:  []string{},
-		loadedFiles: make(map[string][]string),
+		cssFiles:   cssFiles,
+		cssClasses: cssClasses,
+		alwaysLoad: []string{},
 	}
 }
 
-// Class retrieves the CSS class name for a given class path and registers the file in the loading list if a context ID is set.
+// Class retrieves the CSS class name for a given class path and registers the file in the loading list if the context holds a file collector.
 // The class path is in the format <filepath>.<classname> (dot-separated).
 //
-// The CSS class name returned is the one to be used in code. This can be a modified class name when using PostCSS modules.
+// The CSS class name returned is the one to be used in code. This is a modified, file-scoped class name when the
+// styles were processed with scoped classes (CSS Modules).
 //
 // Parameters:
 // - ctx: The context for the request, used to manage the class files that are used for the current context.
 // - name: The class path of the CSS class to retrieve.
 //
 // Returns:
-// - The CSS class name as a string.
+// - The CSS class name as a string. When the class composes other classes, their names follow it, space-separated.
 // - An error if the class name is not found.
 func (c *CSSLoader) Class(ctx context.Context, name string) (string, error) {
 	class, ok := c.cssClasses[name]
@@ -44,37 +49,41 @@ func (c *CSSLoader) Class(ctx context.Context, name string) (string, error) {
 		return "", fmt.Errorf("class %s not found", name)
 	}
 
-	cssContextId, ok := fromContext(ctx)
+	collector, ok := fromContext(ctx)
 	if ok {
-		// Adds to list of files that we need to load for the current context
-		c.registerFileForLoading(cssContextId, class)
+		// Adds to list of files that we need to load for the current context.
+		// Composed files are registered first so the composing class can override them.
+		for _, composed := range class.Composes {
+			c.registerFileForLoading(collector, composed)
+		}
+		c.registerFileForLoading(collector, class)
+	}
+
+	if len(class.Composes) == 0 {
+		return class.Class, nil
 	}
 
-	return class.Class, nil
+	names := make([]string, 0, len(class.Composes)+1)
+	names = append(names, class.Class)
+	for _, composed := range class.Composes {
+		names = append(names, composed.Class)
+	}
+	return strings.Join(names, " "), nil
 }
 
-// registerFileForLoading registers a CSS file for loading in the given context.
-// It ensures that the file is added only if it is not already present in the list.
+// registerFileForLoading registers a CSS file for loading in the given collector.
+// The collector ensures that the file is added only if it is not already present in the list.
 //
 // Parameters:
-// - contextId: A unique identifier for the context.
+// - collector: The file collector for the current context.
 // - class: The CSSClass instance containing the file path and class name.
-func (c *CSSLoader) registerFileForLoading(contextId string, class CSSClass) {
-	loadedFiles, ok := c.loadedFiles[contextId]
-	if !ok {
-		loadedFiles = append([]string{}, c.alwaysLoad...)
+func (c *CSSLoader) registerFileForLoading(collector *fileCollector, class CSSClass) {
+	if class.Path == "" {
+		// Global classes are not backed by a file
+		return
 	}
-
 	path, _ := c.GetPath(class.Path)
-
-	for _, val := range loadedFiles {
-		if path == val {
-			return
-		}
-	}
-
-	loadedFiles = append(loadedFiles, path)
-	c.loadedFiles[contextId] = loadedFiles
+	collector.add(path)
 }
 
 // GetPath retrieves the versioned file path for a given CSS file path.
@@ -94,8 +103,9 @@ func (c *CSSLoader) GetPath(name string) (string, error) {
 	return path, nil
 }
 
-// LoadedFiles retrieves the list of CSS files loaded for the given context and clears the record.
-// This should be called at the last moment as it clears the loaded files for the current context.
+// LoadedFiles retrieves the list of CSS files loaded for the given context.
+// This should be called at the last moment as files registered afterwards are not included.
+// The record is held by the context, so there is nothing to clear once the request is done.
 //
 // Parameters:
 // - ctx: The context for the request, used to manage the lifecycle of the request.
@@ -103,16 +113,30 @@ func (c *CSSLoader) GetPath(name string) (string, error) {
 // Returns:
 // - A slice of strings containing the paths of the loaded CSS files.
 func (c *CSSLoader) LoadedFiles(ctx context.Context) []string {
-	cssContextId, ok := fromContext(ctx)
+	collector, ok := fromContext(ctx)
 	if !ok {
 		return make([]string, 0)
 	}
-	files, ok := c.loadedFiles[cssContextId]
-	if !ok {
+	loaded := collector.list()
+	if len(loaded) == 0 {
 		return make([]string, 0)
 	}
 
-	delete(c.loadedFiles, cssContextId)
+	files := append([]string{}, c.alwaysLoad...)
+	for _, path := range loaded {
+		if !contains(files, path) {
+			files = append(files, path)
+		}
+	}
 
 	return files
 }
+
+func contains(values []string, value string) bool {
+	for _, val := range values {
+		if val == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package pristinecss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestLoader() *CSSLoader {
+	return NewLoader(
+		map[string]string{
+			"main.css":  "/d3djskdjif/main.css",
+			"reset.css": "/d41d8cd98f/reset.css",
+		},
+		map[string]CSSClass{
+			"main.button": {Path: "main.css", Class: "button"},
+			"main.header": {Path: "main.css", Class: "header"},
+			"reset.body":  {Path: "reset.css", Class: "body"},
+		},
+	)
+}
+
+func TestLoadedFiles(t *testing.T) {
+	loader := newTestLoader()
+	ctx := NewCSSContext(context.Background())
+
+	for _, name := range []string{"reset.body", "main.button", "main.header"} {
+		if _, err := loader.Class(ctx, name); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	expected := []string{"/d41d8cd98f/reset.css", "/d3djskdjif/main.css"}
+	if diff := cmp.Diff(expected, loader.LoadedFiles(ctx)); diff != "" {
+		t.Errorf("Loaded files mismatch (-want +got):\n%s", diff)
+	}
+
+	if files := loader.LoadedFiles(NewCSSContext(context.Background())); len(files) != 0 {
+		t.Errorf("Expected a new context to have no loaded files, got %v", files)
+	}
+	if files := loader.LoadedFiles(context.Background()); len(files) != 0 {
+		t.Errorf("Expected a context without a collector to have no loaded files, got %v", files)
+	}
+}
+
+func TestClassNotFound(t *testing.T) {
+	loader := newTestLoader()
+	if _, err := loader.Class(context.Background(), "main.missing"); err == nil {
+		t.Errorf("Expected an error for a missing class")
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	loader := newTestLoader()
+	handler := CSSContextIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("class")
+		if _, err := loader.Class(r.Context(), name); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, loader.LoadedFiles(r.Context()))
+	}))
+
+	cases := map[string]string{
+		"main.button": "[/d3djskdjif/main.css]",
+		"reset.body":  "[/d41d8cd98f/reset.css]",
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		for class, expected := range cases {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?class="+class, nil))
+				if got := rec.Body.String(); got != expected {
+					t.Errorf("Request for %s loaded %s, expected %s", class, got, expected)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+func TestClassComposes(t *testing.T) {
+	loader := NewLoader(
+		map[string]string{
+			"main.css":   "/d3djskdjif/main.css",
+			"shared.css": "/a1b2c3d4e5/shared.css",
+		},
+		map[string]CSSClass{
+			"main.button": {Path: "main.css", Class: "button_1", Composes: []CSSClass{
+				{Path: "shared.css", Class: "base_2"},
+				{Class: "clearfix"},
+			}},
+		},
+	)
+	ctx := NewCSSContext(context.Background())
+
+	class, err := loader.Class(ctx, "main.button")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "button_1 base_2 clearfix"; class != expected {
+		t.Errorf("Expected class %q, got %q", expected, class)
+	}
+
+	expected := []string{"/a1b2c3d4e5/shared.css", "/d3djskdjif/main.css"}
+	if diff := cmp.Diff(expected, loader.LoadedFiles(ctx)); diff != "" {
+		t.Errorf("Loaded files mismatch (-want +got):\n%s", diff)
+	}
+}
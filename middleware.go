@@ -2,42 +2,56 @@ package pristinecss
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"net/http"
+	"sync"
 )
 
-// Key to use when setting the request ID in the context
+// Key to use when setting the file collector in the context
 type key int
 
 const cssContextIDKey key = 0
 
-// CSSContextIDMiddleware is a middleware that adds an ID to the context of each request for styles.
+// CSSContextIDMiddleware is a middleware that adds a file collector to the context of each request for styles.
 func CSSContextIDMiddleware(next http.Handler) http.Handler {
-    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        requestID, err := generateRandomID(16) // Generates a 32-character hex string (16 bytes)
-        if err != nil {
-            http.Error(w, "Failed to generate request ID", http.StatusInternalServerError)
-            return
-        }
-        ctx := context.WithValue(r.Context(), cssContextIDKey, requestID)
-        r = r.WithContext(ctx)
-        next.ServeHTTP(w, r)
-    })
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(NewCSSContext(r.Context()))
+		next.ServeHTTP(w, r)
+	})
 }
 
-// fromContext retrieves the request ID from the context
-func fromContext(ctx context.Context) (string, bool) {
-	requestID, ok := ctx.Value(cssContextIDKey).(string)
-	return requestID, ok
+// NewCSSContext returns a copy of the context holding a new, empty file collector.
+// This is used by CSSContextIDMiddleware and can be used directly when rendering outside an HTTP handler.
+func NewCSSContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cssContextIDKey, &fileCollector{})
 }
 
-// generateRandomID generates a cryptographically secure random ID.
-func generateRandomID(length int) (string, error) {
-    bytes := make([]byte, length)
-    if _, err := rand.Read(bytes); err != nil {
-        return "", err // return an empty string and the error
-    }
-    return hex.EncodeToString(bytes), nil
+// fromContext retrieves the file collector from the context
+func fromContext(ctx context.Context) (*fileCollector, bool) {
+	collector, ok := ctx.Value(cssContextIDKey).(*fileCollector)
+	return collector, ok
 }
 
+// fileCollector records the CSS files used while handling a single request.
+// It is owned by the request's context so it is released along with the request.
+type fileCollector struct {
+	mu    sync.Mutex
+	files []string
+}
+
+// add records the file if it has not already been recorded.
+func (fc *fileCollector) add(path string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if !contains(fc.files, path) {
+		fc.files = append(fc.files, path)
+	}
+}
+
+// list returns a copy of the recorded files in the order they were first used.
+func (fc *fileCollector) list() []string {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return append([]string{}, fc.files...)
+}
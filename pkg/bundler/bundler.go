@@ -0,0 +1,324 @@
+// Package bundler inlines the local @import rules of stylesheets into a single stylesheet.
+package bundler
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+	"github.com/aledsdavies/pristinecss/pkg/printer"
+)
+
+// ErrImportCycle is wrapped by the error returned when files import each other in a cycle.
+var ErrImportCycle = errors.New("import cycle")
+
+// Bundle parses the entry files and returns a single stylesheet in which every local @import is
+// replaced by the content of the imported file.
+//
+// Imports are resolved relative to the importing file, or to the root of fsys when they start
+// with a '/'. Imported content is wrapped in @layer, @supports and @media blocks equivalent to
+// the import's conditions. Imports of remote URLs, such as https://..., are left untouched and
+// hoisted to the top of the bundle, as @import is only allowed before other rules. A file
+// imported more than once with the same conditions is only included at its last import, which is
+// where its rules take effect in the cascade.
+//
+// Parameters:
+// - fsys: The filesystem the entries and imports are read from.
+// - entries: The paths of the entry files within fsys, bundled in order.
+//
+// Returns:
+// - The bundled stylesheet. Each node records the file it was parsed from in its Position.
+// - An error if a file could not be read or parsed, or if the imports form a cycle. The error
+// names the chain of imports that led to the file.
+func Bundle(fsys fs.FS, entries ...string) (*parser.Stylesheet, error) {
+	b := &bundler{
+		fsys:  fsys,
+		files: make(map[string]*file),
+	}
+
+	for _, entry := range entries {
+		if err := b.visit(path.Clean(entry), nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.build()
+}
+
+type bundler struct {
+	fsys  fs.FS
+	files map[string]*file
+
+	charset     parser.Node
+	remote      []*parser.ImportAtRule
+	occurrences []occurrence
+}
+
+// file is a parsed file split into the parts that are placed separately in the bundle.
+type file struct {
+	// prelude holds the @layer statements before the imports, which declare the order of layers
+	// and so must stay ahead of the imported content
+	prelude []parser.Node
+	imports []*parser.ImportAtRule
+	rules   []parser.Node
+}
+
+// occurrence is a place where the content of a file is included in the bundle.
+type occurrence struct {
+	path string
+	// conditions are the imports that led to the file, outermost first
+	conditions []*parser.ImportAtRule
+	prelude    bool
+}
+
+// visit records the occurrences of the file and everything it imports, depth first, so that
+// imported content is placed before the content of the importing file.
+func (b *bundler) visit(filePath string, conditions []*parser.ImportAtRule, chain []string) error {
+	for i, p := range chain {
+		if p == filePath {
+			cycle := append(append([]string{}, chain[i:]...), filePath)
+			return fmt.Errorf("%w: %s", ErrImportCycle, strings.Join(cycle, " -> "))
+		}
+	}
+	chain = append(chain, filePath)
+
+	f, err := b.load(filePath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", strings.Join(chain, " -> "), err)
+	}
+
+	b.occurrences = append(b.occurrences, occurrence{path: filePath, conditions: conditions, prelude: true})
+	for _, imp := range f.imports {
+		url := importURL(imp)
+		if isRemote(url) {
+			hoisted, err := hoist(imp, conditions)
+			if err != nil {
+				return fmt.Errorf("%s: %w", strings.Join(chain, " -> "), err)
+			}
+			b.remote = append(b.remote, hoisted)
+			continue
+		}
+
+		target := path.Join(path.Dir(filePath), url)
+		if strings.HasPrefix(url, "/") {
+			target = strings.TrimPrefix(path.Clean(url), "/")
+		}
+		nested := append(append([]*parser.ImportAtRule{}, conditions...), imp)
+		if err := b.visit(target, nested, chain); err != nil {
+			return err
+		}
+	}
+	b.occurrences = append(b.occurrences, occurrence{path: filePath, conditions: conditions})
+
+	return nil
+}
+
+// load reads and parses the file, caching the result so files imported twice are parsed once.
+func (b *bundler) load(filePath string) (*file, error) {
+	if f, ok := b.files[filePath]; ok {
+		return f, nil
+	}
+
+	content, err := fs.ReadFile(b.fsys, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	stylesheet, parseErrors := parser.Parse(lexer.LexBytes(content), parser.WithSource(filePath))
+	if len(parseErrors) > 0 {
+		errs := make([]error, len(parseErrors))
+		for i, parseErr := range parseErrors {
+			errs[i] = parseErr
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	f := &file{}
+	inPrelude := true
+	for _, rule := range stylesheet.Rules {
+		switch r := rule.(type) {
+		case *parser.CharsetAtRule:
+			// Only the first @charset of the bundle is kept, as it must be the first rule
+			if b.charset == nil {
+				b.charset = r
+			}
+			continue
+		case *parser.ImportAtRule:
+			if inPrelude {
+				f.imports = append(f.imports, r)
+				continue
+			}
+		case *parser.LayerAtRule:
+			if inPrelude && r.IsStatement && len(f.imports) == 0 {
+				f.prelude = append(f.prelude, r)
+				continue
+			}
+		case *parser.Comment:
+			if inPrelude && len(f.imports) == 0 {
+				f.prelude = append(f.prelude, r)
+				continue
+			}
+		}
+		// An @import after any other rule is ignored by browsers, so it is left in place
+		if _, ok := rule.(*parser.Comment); !ok {
+			inPrelude = false
+		}
+		f.rules = append(f.rules, rule)
+	}
+
+	b.files[filePath] = f
+	return f, nil
+}
+
+// build assembles the bundle from the recorded occurrences, keeping only the last occurrence of
+// each file with the same conditions.
+func (b *bundler) build() (*parser.Stylesheet, error) {
+	last := make(map[string]int)
+	keys := make([]string, len(b.occurrences))
+	for i, occ := range b.occurrences {
+		key, err := occurrenceKey(occ, i)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+		last[key] = i
+	}
+
+	stylesheet := parser.NewStylesheet()
+	if b.charset != nil {
+		stylesheet.Rules = append(stylesheet.Rules, b.charset)
+	}
+	for _, imp := range b.remote {
+		stylesheet.Rules = append(stylesheet.Rules, imp)
+	}
+
+	for i, occ := range b.occurrences {
+		if last[keys[i]] != i {
+			continue
+		}
+
+		f := b.files[occ.path]
+		rules := f.rules
+		if occ.prelude {
+			rules = f.prelude
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		stylesheet.Rules = append(stylesheet.Rules, wrap(rules, occ.conditions)...)
+	}
+
+	return stylesheet, nil
+}
+
+// wrap wraps the rules in the blocks equivalent to the conditions of each import, so that
+// @import url(a.css) layer(x) supports(c) print becomes @media print { @supports c { @layer x { ... } } }.
+func wrap(rules []parser.Node, conditions []*parser.ImportAtRule) []parser.Node {
+	for i := len(conditions) - 1; i >= 0; i-- {
+		imp := conditions[i]
+		if imp.Layer != nil {
+			rules = []parser.Node{&parser.LayerAtRule{Position: imp.Position, Names: layerNames(imp.Layer), Rules: rules}}
+		}
+		if imp.Supports != nil {
+			rules = []parser.Node{&parser.SupportsAtRule{Position: imp.Position, Condition: imp.Supports, Rules: rules}}
+		}
+		if len(imp.Media.Queries) > 0 {
+			rules = []parser.Node{&parser.MediaAtRule{Position: imp.Position, Name: []byte(parser.Media), Query: imp.Media, Rules: rules}}
+		}
+	}
+	return rules
+}
+
+// hoist returns a remote import with the conditions of the imports that led to it, so it can be
+// moved to the top of the bundle. It is an error if the conditions cannot be combined into a
+// single import.
+func hoist(imp *parser.ImportAtRule, conditions []*parser.ImportAtRule) (*parser.ImportAtRule, error) {
+	hoisted := *imp
+	for _, outer := range conditions {
+		if (outer.Layer != nil && hoisted.Layer != nil) ||
+			(outer.Supports != nil && hoisted.Supports != nil) ||
+			(len(outer.Media.Queries) > 0 && len(hoisted.Media.Queries) > 0) {
+			return nil, fmt.Errorf("cannot hoist the import of %s out of nested import conditions", importURL(imp))
+		}
+		if outer.Layer != nil {
+			hoisted.Layer = outer.Layer
+		}
+		if outer.Supports != nil {
+			hoisted.Supports = outer.Supports
+		}
+		if len(outer.Media.Queries) > 0 {
+			hoisted.Media = outer.Media
+		}
+	}
+	return &hoisted, nil
+}
+
+// importURL returns the URL of the import, from either a string or url().
+func importURL(imp *parser.ImportAtRule) string {
+	switch v := imp.URL.(type) {
+	case *parser.StringValue:
+		return string(v.Value)
+	case *parser.FunctionValue:
+		if len(v.Arguments) == 1 {
+			switch arg := v.Arguments[0].(type) {
+			case *parser.StringValue:
+				return string(arg.Value)
+			case *parser.BasicValue:
+				return string(arg.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// isRemote reports whether the URL refers to a resource outside the bundled filesystem.
+func isRemote(url string) bool {
+	return strings.Contains(url, "://") || strings.HasPrefix(url, "//") || strings.HasPrefix(url, "data:")
+}
+
+// layerNames returns the names of the layer an import is placed in, none for an anonymous layer.
+func layerNames(layer parser.Value) [][]byte {
+	fn, ok := layer.(*parser.FunctionValue)
+	if !ok {
+		return nil
+	}
+	var name []byte
+	for _, arg := range fn.Arguments {
+		if bv, ok := arg.(*parser.BasicValue); ok {
+			name = append(name, bv.Value...)
+		}
+	}
+	return [][]byte{name}
+}
+
+// occurrenceKey returns a key that is equal for occurrences of the same part of a file under the
+// same conditions. Each import into an anonymous layer creates a distinct layer, so those
+// occurrences get a key of their own from their index.
+func occurrenceKey(occ occurrence, index int) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(occ.path)
+	if occ.prelude {
+		sb.WriteString("\x00prelude")
+	}
+	for _, imp := range occ.conditions {
+		if _, anonymous := imp.Layer.(*parser.BasicValue); anonymous {
+			return fmt.Sprintf("%s\x00%d", occ.path, index), nil
+		}
+		if imp.Layer == nil && imp.Supports == nil && len(imp.Media.Queries) == 0 {
+			continue
+		}
+		conditions := *imp
+		conditions.URL = &parser.StringValue{}
+		printed, err := printer.Print(&conditions, printer.WithCompact())
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(0)
+		sb.WriteString(printed)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,160 @@
+package bundler_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aledsdavies/pristinecss/pkg/bundler"
+	"github.com/aledsdavies/pristinecss/pkg/printer"
+	"github.com/aledsdavies/pristinecss/pkg/sourcemap"
+	"github.com/google/go-cmp/cmp"
+)
+
+func mapFS(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+func TestBundle(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "Inlines imports relative to the importing file",
+			files: map[string]string{
+				"main.css":              `@import "components/button.css"; .main { color: red; }`,
+				"components/button.css": `@import url("../base.css"); .button { color: blue; }`,
+				"base.css":              `body { margin: 0; }`,
+			},
+			expected: "body{margin:0;}.button{color:blue;}.main{color:red;}",
+		},
+		{
+			name: "Wraps imports in their conditions",
+			files: map[string]string{
+				"main.css": `@import url("a.css") layer(base) supports(display: grid) screen; @import "b.css" layer;`,
+				"a.css":    `.a { display: grid; }`,
+				"b.css":    `.b { color: red; }`,
+			},
+			expected: "@media screen{@supports (display:grid){@layer base{.a{display:grid;}}}}@layer{.b{color:red;}}",
+		},
+		{
+			name: "Hoists remote imports",
+			files: map[string]string{
+				"main.css": `@import "a.css"; @import url("https://fonts.example.com/font.css");`,
+				"a.css":    `@import "https://cdn.example.com/reset.css" print; .a { color: red; }`,
+			},
+			expected: `@import "https://cdn.example.com/reset.css" print;@import url("https://fonts.example.com/font.css");.a{color:red;}`,
+		},
+		{
+			name: "Keeps the last import of a file",
+			files: map[string]string{
+				"main.css": `@import "a.css"; @import "b.css"; @import "c.css";`,
+				"a.css":    `.a { color: red; }`,
+				"b.css":    `.b { color: blue; }`,
+				"c.css":    `@import "a.css"; .c { color: green; }`,
+			},
+			expected: ".b{color:blue;}.a{color:red;}.c{color:green;}",
+		},
+		{
+			name: "Keeps layer statements ahead of imports",
+			files: map[string]string{
+				"main.css":  `@charset "utf-8"; @layer base, theme; @import "theme.css" layer(theme); .main { color: red; }`,
+				"theme.css": `@charset "utf-8"; .theme { color: blue; }`,
+			},
+			expected: `@charset "utf-8";@layer base,theme;@layer theme{.theme{color:blue;}}.main{color:red;}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stylesheet, err := bundler.Bundle(mapFS(tt.files), "main.css")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			output, err := printer.Print(stylesheet, printer.WithCompact())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, output); diff != "" {
+				t.Errorf("Output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBundleErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		message string
+		cycle   bool
+	}{
+		{
+			name: "Cycle",
+			files: map[string]string{
+				"main.css": `@import "a.css";`,
+				"a.css":    `@import "b.css";`,
+				"b.css":    `@import "a.css";`,
+			},
+			message: "import cycle: a.css -> b.css -> a.css",
+			cycle:   true,
+		},
+		{
+			name: "Missing file",
+			files: map[string]string{
+				"main.css": `@import "a.css";`,
+				"a.css":    `@import "missing.css";`,
+			},
+			message: "main.css -> a.css -> missing.css: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bundler.Bundle(mapFS(tt.files), "main.css")
+			if err == nil {
+				t.Fatalf("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error to contain %q, got %q", tt.message, err.Error())
+			}
+			if errors.Is(err, bundler.ErrImportCycle) != tt.cycle {
+				t.Errorf("Expected errors.Is(err, ErrImportCycle) to be %v", tt.cycle)
+			}
+		})
+	}
+}
+
+func TestBundleSourceMap(t *testing.T) {
+	fsys := mapFS(map[string]string{
+		"main.css": "@import \"a.css\";\n.main { color: red; }",
+		"a.css":    ".a { color: blue; }",
+	})
+
+	stylesheet, err := bundler.Bundle(fsys, "main.css")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := sourcemap.New("bundle.css")
+	if _, err := printer.Print(stylesheet, printer.WithCompact(), printer.WithSourceMap(g)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []sourcemap.Mapping{
+		{GeneratedLine: 1, GeneratedColumn: 1, Source: "a.css", OriginalLine: 1, OriginalColumn: 1},
+		{GeneratedLine: 1, GeneratedColumn: 4, Source: "a.css", OriginalLine: 1, OriginalColumn: 6},
+		{GeneratedLine: 1, GeneratedColumn: 16, Source: "main.css", OriginalLine: 2, OriginalColumn: 1},
+		{GeneratedLine: 1, GeneratedColumn: 22, Source: "main.css", OriginalLine: 2, OriginalColumn: 9},
+	}
+	if diff := cmp.Diff(expected, g.Mappings()); diff != "" {
+		t.Errorf("Mappings mismatch (-want +got):\n%s", diff)
+	}
+}
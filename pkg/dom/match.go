@@ -0,0 +1,321 @@
+// Package dom matches selectors against a document tree, such as the markup rendered by a
+// component, so that the rules of a stylesheet can be checked against the elements they target.
+package dom
+
+import (
+	"strings"
+
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+)
+
+// Element is an element of a document tree. Its methods only see elements, so text and
+// comments are not part of the tree.
+//
+// Elements are compared with ==, so an implementation should use a comparable type such as a
+// pointer, and return a nil interface rather than a nil pointer when there is no element.
+type Element interface {
+	// Tag returns the element's tag name, such as "div"
+	Tag() string
+	// Attribute returns the value of an attribute and whether the element has it
+	Attribute(name string) (string, bool)
+	// Parent returns the parent element, or nil for the root
+	Parent() Element
+	// PreviousSibling returns the element before this one in its parent, or nil
+	PreviousSibling() Element
+	// NextSibling returns the element after this one in its parent, or nil
+	NextSibling() Element
+	// Children returns the child elements in document order
+	Children() []Element
+}
+
+// Match reports whether any selector of the list matches the element.
+//
+// Tag names are compared case-insensitively, and classes, ids and attribute values
+// case-sensitively unless an attribute selector has the i modifier. Namespace prefixes are
+// ignored. Pseudo-elements and pseudo-classes that depend on state, such as :hover, never match.
+// :empty matches elements without child elements, as text is not part of the tree. The nesting
+// selector is not resolved against an enclosing rule, so it only matches the root.
+//
+// Parameters:
+// - list: The selector list, such as from parser.ParseSelector.
+// - el: The element to match.
+//
+// Returns:
+// - True if the element matches the selector list.
+func Match(list parser.SelectorList, el Element) bool {
+	for _, complex := range list {
+		if matchComplex(complex.Compounds, len(complex.Compounds)-1, el, nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// QuerySelectorAll returns the descendants of root that match the selector list, like the DOM
+// method of the same name. Combinators can match elements outside root, such as its ancestors.
+//
+// Parameters:
+// - root: The element whose descendants are searched. It is not matched itself.
+// - list: The selector list, such as from parser.ParseSelector.
+//
+// Returns:
+// - The matching elements in document order.
+func QuerySelectorAll(root Element, list parser.SelectorList) []Element {
+	var matches []Element
+	walkDescendants(root, func(el Element) {
+		if Match(list, el) {
+			matches = append(matches, el)
+		}
+	})
+	return matches
+}
+
+func walkDescendants(el Element, visit func(Element)) {
+	for _, child := range el.Children() {
+		visit(child)
+		walkDescendants(child, visit)
+	}
+}
+
+// matchComplex reports whether el matches compounds[i] and the compounds before it match the
+// elements its combinators lead to, working from right to left. For the relative selectors of
+// :has(), anchor is the element that the first compound must be related to.
+func matchComplex(compounds []*parser.CompoundSelector, i int, el Element, anchor Element) bool {
+	compound := compounds[i]
+	if !matchCompound(compound, el) {
+		return false
+	}
+	if i == 0 {
+		return anchor == nil || related(compound.Combinator, anchor, el)
+	}
+
+	switch compound.Combinator {
+	case parser.Child:
+		parent := el.Parent()
+		return parent != nil && matchComplex(compounds, i-1, parent, anchor)
+	case parser.NextSibling:
+		prev := el.PreviousSibling()
+		return prev != nil && matchComplex(compounds, i-1, prev, anchor)
+	case parser.SubsequentSibling:
+		for prev := el.PreviousSibling(); prev != nil; prev = prev.PreviousSibling() {
+			if matchComplex(compounds, i-1, prev, anchor) {
+				return true
+			}
+		}
+		return false
+	default:
+		for parent := el.Parent(); parent != nil; parent = parent.Parent() {
+			if matchComplex(compounds, i-1, parent, anchor) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// related reports whether el is related to anchor by the combinator. A relative selector
+// without a leading combinator is a descendant of its anchor.
+func related(combinator parser.CombinatorType, anchor, el Element) bool {
+	switch combinator {
+	case parser.Child:
+		return el.Parent() == anchor
+	case parser.NextSibling:
+		return el.PreviousSibling() == anchor
+	case parser.SubsequentSibling:
+		for prev := el.PreviousSibling(); prev != nil; prev = prev.PreviousSibling() {
+			if prev == anchor {
+				return true
+			}
+		}
+		return false
+	default:
+		for parent := el.Parent(); parent != nil; parent = parent.Parent() {
+			if parent == anchor {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matchRelative reports whether any relative selector of the list matches an element related to
+// the anchor, for :has().
+func matchRelative(list parser.SelectorList, anchor Element) bool {
+	found := false
+	visit := func(el Element) {
+		if found {
+			return
+		}
+		for _, complex := range list {
+			if matchComplex(complex.Compounds, len(complex.Compounds)-1, el, anchor) {
+				found = true
+				return
+			}
+		}
+	}
+
+	// Relative selectors reach the anchor's descendants, and through sibling combinators its
+	// following siblings and their descendants
+	walkDescendants(anchor, visit)
+	for sibling := anchor.NextSibling(); sibling != nil && !found; sibling = sibling.NextSibling() {
+		visit(sibling)
+		walkDescendants(sibling, visit)
+	}
+	return found
+}
+
+func matchCompound(compound *parser.CompoundSelector, el Element) bool {
+	for _, simple := range compound.Selectors {
+		if !matchSimple(simple, el) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchSimple(simple parser.SimpleSelector, el Element) bool {
+	switch s := simple.(type) {
+	case *parser.TypeSelector:
+		return strings.EqualFold(el.Tag(), string(s.Name))
+	case *parser.UniversalSelector:
+		return true
+	case *parser.NestingSelector:
+		// Outside of a nested rule, the nesting selector matches the same as :scope
+		return el.Parent() == nil
+	case *parser.ClassSelector:
+		class, _ := el.Attribute("class")
+		return includes(class, string(s.Name))
+	case *parser.IDSelector:
+		id, ok := el.Attribute("id")
+		return ok && id == string(s.Name)
+	case *parser.AttributeSelector:
+		return matchAttribute(s, el)
+	case *parser.PseudoSelector:
+		return matchPseudo(s, el)
+	}
+	return false
+}
+
+// includes reports whether a whitespace separated list contains the word.
+func includes(list, word string) bool {
+	for _, field := range strings.Fields(list) {
+		if field == word {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAttribute(attr *parser.AttributeSelector, el Element) bool {
+	value, ok := el.Attribute(string(attr.Name))
+	if !ok {
+		return false
+	}
+	expected := string(attr.Value)
+	if attr.Modifier == parser.CaseInsensitive {
+		value = strings.ToLower(value)
+		expected = strings.ToLower(expected)
+	}
+
+	switch attr.Matcher {
+	case parser.MatchExists:
+		return true
+	case parser.MatchEquals:
+		return value == expected
+	case parser.MatchIncludes:
+		return includes(value, expected)
+	case parser.MatchDash:
+		return value == expected || strings.HasPrefix(value, expected+"-")
+	case parser.MatchPrefix:
+		return expected != "" && strings.HasPrefix(value, expected)
+	case parser.MatchSuffix:
+		return expected != "" && strings.HasSuffix(value, expected)
+	case parser.MatchSubstring:
+		return expected != "" && strings.Contains(value, expected)
+	}
+	return false
+}
+
+func matchPseudo(pseudo *parser.PseudoSelector, el Element) bool {
+	if pseudo.Element {
+		return false
+	}
+
+	switch strings.ToLower(string(pseudo.Name)) {
+	case "is", "where":
+		return Match(pseudo.Selectors, el)
+	case "not":
+		return !Match(pseudo.Selectors, el)
+	case "has":
+		return matchRelative(pseudo.Selectors, el)
+	case "root":
+		return el.Parent() == nil
+	case "empty":
+		return len(el.Children()) == 0
+	case "first-child":
+		return el.PreviousSibling() == nil
+	case "last-child":
+		return el.NextSibling() == nil
+	case "only-child":
+		return el.PreviousSibling() == nil && el.NextSibling() == nil
+	case "first-of-type":
+		return countSiblings(el, Element.PreviousSibling, sameType(el)) == 0
+	case "last-of-type":
+		return countSiblings(el, Element.NextSibling, sameType(el)) == 0
+	case "only-of-type":
+		return countSiblings(el, Element.PreviousSibling, sameType(el)) == 0 &&
+			countSiblings(el, Element.NextSibling, sameType(el)) == 0
+	case "nth-child":
+		return matchNth(pseudo.Nth, el, Element.PreviousSibling)
+	case "nth-last-child":
+		return matchNth(pseudo.Nth, el, Element.NextSibling)
+	case "nth-of-type":
+		return pseudo.Nth != nil && nthMatches(pseudo.Nth, countSiblings(el, Element.PreviousSibling, sameType(el))+1)
+	case "nth-last-of-type":
+		return pseudo.Nth != nil && nthMatches(pseudo.Nth, countSiblings(el, Element.NextSibling, sameType(el))+1)
+	}
+	return false
+}
+
+// sameType returns a filter for the elements with the same tag name as el.
+func sameType(el Element) func(Element) bool {
+	return func(sibling Element) bool {
+		return strings.EqualFold(sibling.Tag(), el.Tag())
+	}
+}
+
+// countSiblings counts the siblings in one direction from el that pass the filter.
+func countSiblings(el Element, next func(Element) Element, filter func(Element) bool) int {
+	count := 0
+	for sibling := next(el); sibling != nil; sibling = next(sibling) {
+		if filter(sibling) {
+			count++
+		}
+	}
+	return count
+}
+
+// matchNth matches :nth-child() and :nth-last-child(), which only count the siblings matching
+// the "of" selector list when there is one.
+func matchNth(nth *parser.AnPlusB, el Element, next func(Element) Element) bool {
+	if nth == nil {
+		return false
+	}
+	filter := func(Element) bool { return true }
+	if nth.Of != nil {
+		if !Match(nth.Of, el) {
+			return false
+		}
+		filter = func(sibling Element) bool { return Match(nth.Of, sibling) }
+	}
+	return nthMatches(nth, countSiblings(el, next, filter)+1)
+}
+
+// nthMatches reports whether the 1-based index is A*n+B for some n >= 0.
+func nthMatches(nth *parser.AnPlusB, index int) bool {
+	if nth.A == 0 {
+		return index == nth.B
+	}
+	diff := index - nth.B
+	return diff%nth.A == 0 && diff/nth.A >= 0
+}
@@ -0,0 +1,209 @@
+package dom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+)
+
+// node is an Element for tests. Its name identifies it in the expected results.
+type node struct {
+	name     string
+	tag      string
+	attrs    map[string]string
+	parent   *node
+	children []*node
+}
+
+func (n *node) Tag() string { return n.tag }
+
+func (n *node) Attribute(name string) (string, bool) {
+	value, ok := n.attrs[name]
+	return value, ok
+}
+
+func (n *node) Parent() Element {
+	if n.parent == nil {
+		return nil
+	}
+	return n.parent
+}
+
+func (n *node) sibling(offset int) Element {
+	if n.parent == nil {
+		return nil
+	}
+	for i, child := range n.parent.children {
+		if child == n {
+			if j := i + offset; j >= 0 && j < len(n.parent.children) {
+				return n.parent.children[j]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (n *node) PreviousSibling() Element { return n.sibling(-1) }
+func (n *node) NextSibling() Element     { return n.sibling(1) }
+
+func (n *node) Children() []Element {
+	children := make([]Element, len(n.children))
+	for i, child := range n.children {
+		children[i] = child
+	}
+	return children
+}
+
+// el creates a node. The name is "tag" or "tag:name", and attrs alternate names and values.
+func el(name string, attrs []string, children ...*node) *node {
+	tag, _, _ := strings.Cut(name, ":")
+	n := &node{name: name, tag: tag, attrs: make(map[string]string), children: children}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		n.attrs[attrs[i]] = attrs[i+1]
+	}
+	for _, child := range children {
+		child.parent = n
+	}
+	return n
+}
+
+func document() *node {
+	return el("html", nil,
+		el("body", nil,
+			el("header", []string{"id", "top", "class", "site dark"},
+				el("nav", nil,
+					el("ul", []string{"class", "menu"},
+						el("li:1", []string{"class", "item first"}, el("a:home", []string{"href", "https://example.com"})),
+						el("li:2", []string{"class", "item"}, el("a:about", []string{"href", "/about", "lang", "en-GB"})),
+						el("li:3", []string{"class", "item active"}, el("a:contact", []string{"href", "/contact.PDF", "title", "Contact Us"})),
+						el("li:4", []string{"class", "item"}),
+					),
+				),
+			),
+			el("main", nil,
+				el("p:1", nil),
+				el("img", []string{"alt", "Logo"}),
+				el("p:2", nil, el("span", nil)),
+				el("h2", nil),
+				el("p:3", []string{"hidden", ""}),
+			),
+		),
+	)
+}
+
+func TestQuerySelectorAll(t *testing.T) {
+	tests := []struct {
+		selector string
+		expected []string
+	}{
+		// Simple and compound selectors
+		{selector: "LI", expected: []string{"li:1", "li:2", "li:3", "li:4"}},
+		{selector: ".item.active", expected: []string{"li:3"}},
+		{selector: "#top", expected: []string{"header"}},
+		{selector: "header.dark#top", expected: []string{"header"}},
+		{selector: "*|nav", expected: []string{"nav"}},
+		{selector: ".missing", expected: nil},
+
+		// Combinators
+		{selector: "nav a", expected: []string{"a:home", "a:about", "a:contact"}},
+		{selector: "ul > a", expected: nil},
+		{selector: "main > p", expected: []string{"p:1", "p:2", "p:3"}},
+		{selector: "img + p", expected: []string{"p:2"}},
+		{selector: "img ~ p", expected: []string{"p:2", "p:3"}},
+		{selector: "body > header li.first ~ li > a", expected: []string{"a:about", "a:contact"}},
+		{selector: "h2, img", expected: []string{"img", "h2"}},
+
+		// Attribute matchers
+		{selector: "[hidden]", expected: []string{"p:3"}},
+		{selector: "[href='/about']", expected: []string{"a:about"}},
+		{selector: "[class~=item]", expected: []string{"li:1", "li:2", "li:3", "li:4"}},
+		{selector: "[lang|=en]", expected: []string{"a:about"}},
+		{selector: "[href^=https]", expected: []string{"a:home"}},
+		{selector: "[href$='.pdf']", expected: nil},
+		{selector: "[href$='.pdf' i]", expected: []string{"a:contact"}},
+		{selector: "[title*='Us' s]", expected: []string{"a:contact"}},
+		{selector: "[href^='']", expected: nil},
+
+		// Structural pseudo-classes
+		{selector: ":root", expected: nil},
+		{selector: "li:first-child", expected: []string{"li:1"}},
+		{selector: "li:last-child", expected: []string{"li:4"}},
+		{selector: "a:only-child", expected: []string{"a:home", "a:about", "a:contact"}},
+		{selector: "main > :first-of-type", expected: []string{"p:1", "img", "h2"}},
+		{selector: "main > :last-of-type", expected: []string{"img", "h2", "p:3"}},
+		{selector: "main > :only-of-type", expected: []string{"img", "h2"}},
+		{selector: "main > :empty", expected: []string{"p:1", "img", "h2", "p:3"}},
+		{selector: "li:nth-child(odd)", expected: []string{"li:1", "li:3"}},
+		{selector: "li:nth-child(-n+2)", expected: []string{"li:1", "li:2"}},
+		{selector: "li:nth-last-child(2)", expected: []string{"li:3"}},
+		{selector: "li:nth-child(2 of .item:not(.first))", expected: []string{"li:3"}},
+		{selector: "p:nth-of-type(2n)", expected: []string{"p:2"}},
+		{selector: "p:nth-last-of-type(1)", expected: []string{"p:3"}},
+
+		// Logical pseudo-classes
+		{selector: ":is(h2, img)", expected: []string{"img", "h2"}},
+		{selector: "main > :where(p):not(:empty)", expected: []string{"p:2"}},
+		{selector: "li:not(.item)", expected: nil},
+		{selector: "li:has(a)", expected: []string{"li:1", "li:2", "li:3"}},
+		{selector: "ul:has(> li.active)", expected: []string{"ul"}},
+		{selector: "main > :has(+ h2)", expected: []string{"p:2"}},
+		{selector: "img:has(~ p[hidden])", expected: []string{"img"}},
+		{selector: "li:has(> a[lang], + li.active)", expected: []string{"li:2"}},
+
+		// Pseudo-elements and stateful pseudo-classes
+		{selector: "a:hover", expected: nil},
+		{selector: "p::before", expected: nil},
+	}
+
+	root := document()
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			list, errors := parser.ParseSelector(lexer.Lex(strings.NewReader(tt.selector)))
+			if len(errors) > 0 {
+				t.Fatalf("Unexpected errors: %v", errors)
+			}
+
+			var got []string
+			for _, match := range QuerySelectorAll(root, list) {
+				got = append(got, match.(*node).name)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("Expected [%s], got [%s]", strings.Join(tt.expected, ", "), strings.Join(got, ", "))
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	root := document()
+	header := root.children[0].children[0]
+
+	tests := []struct {
+		selector string
+		el       Element
+		expected bool
+	}{
+		{selector: ":root", el: root, expected: true},
+		{selector: "html > body > header", el: header, expected: true},
+		{selector: "main header", el: header, expected: false},
+		{selector: ".site, .other", el: header, expected: true},
+		{selector: "#TOP", el: header, expected: false},
+		{selector: "&", el: root, expected: true},
+		{selector: "& > body", el: root.children[0], expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			list, errors := parser.ParseSelector(lexer.Lex(strings.NewReader(tt.selector)))
+			if len(errors) > 0 {
+				t.Fatalf("Unexpected errors: %v", errors)
+			}
+			if got := Match(list, tt.el); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
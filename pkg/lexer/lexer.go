@@ -2,6 +2,7 @@ package lexer
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"log"
 
@@ -62,14 +63,16 @@ func init() {
 	}
 }
 
+// The pools are shared by every call to the lexer, so they are thread safe to allow lexing from
+// many goroutines at once
 var tokenPool *mempool.Pool[*tokens.Token]
 var lexerPool *mempool.Pool[*lexer]
-var bufferPool *mempool.Pool[*PoolByteBuffer]
 
 func init() {
 	tokenPool = mempool.NewPool(
 		func() *tokens.Token { return &tokens.Token{} },
 		mempool.WithCapacity(1),
+		mempool.WithThreadSafety(true),
 	)
 	lexerPool = mempool.NewPool(
 		func() *lexer {
@@ -78,17 +81,19 @@ func init() {
 			return l
 		},
 		mempool.WithCapacity(10),
-	)
-	bufferPool = mempool.NewPool(
-		func() *PoolByteBuffer { return &PoolByteBuffer{Buffer: bytes.Buffer{}} },
-		mempool.WithCapacity(10),
+		mempool.WithThreadSafety(true),
 	)
 }
 
+// PoolByteBuffer is a bytes.Buffer that can be kept in a mempool.Pool.
+//
+// Deprecated: the lexer no longer pools its input buffers, since the tokens it
+// returns now own the bytes they were lexed from. Use a bytes.Buffer directly.
 type PoolByteBuffer struct {
 	bytes.Buffer
 }
 
+// Erase resets the buffer so it can be reused.
 func (pbb *PoolByteBuffer) Erase() {
 	pbb.Reset()
 }
@@ -109,25 +114,90 @@ type lexer struct {
 	logger       *log.Logger
 }
 
-func Lex(input io.Reader) []tokens.Token {
-	l := lexerPool.Get()
-	defer lexerPool.Put(l)
+type LexerOpt func(*lexerOptions)
+
+type lexerOptions struct {
+	spec      bool
+	chunkSize int
+}
 
-	buf := bufferPool.Get()
-	defer bufferPool.Put(buf)
+// WithSpecMode tokenizes the input following the token definitions of CSS Syntax Level 3 rather
+// than the lexer's default rules, which the parser is built on. See lexSpec for the differences.
+func WithSpecMode() LexerOpt {
+	return func(opts *lexerOptions) {
+		opts.spec = true
+	}
+}
+
+// WithChunkSize sets the number of bytes a Scanner reads from its input at a time. It has no
+// effect on the other entry points, which read the whole input.
+func WithChunkSize(size int) LexerOpt {
+	return func(opts *lexerOptions) {
+		opts.chunkSize = size
+	}
+}
 
-	_, err := io.Copy(&buf.Buffer, input)
+// Lex tokenizes the CSS read from input. It calls log.Fatalf if input cannot be read, so
+// LexReader should be used for input that can fail, such as a network stream.
+//
+// The input is read into a buffer owned by the returned tokens, whose literals are slices of it.
+// The buffer is never reused, so tokens and the nodes parsed from them stay valid after later
+// calls to the lexer.
+func Lex(input io.Reader, opts ...LexerOpt) []tokens.Token {
+	toks, err := LexReader(input, opts...)
 	if err != nil {
 		log.Fatalf("Fatal error reading input: %v", err)
 	}
-	l.input = buf.Bytes()
+	return toks
+}
+
+// LexReader tokenizes the CSS read from input.
+//
+// Parameters:
+// - input: The reader the CSS is read from, until EOF.
+// - opts: Options such as WithSpecMode.
+//
+// Returns:
+// - The tokens, ending with an EOF token. Their literals are slices of a buffer owned by the tokens.
+// - An error if input could not be read, in which case no tokens are returned.
+func LexReader(input io.Reader, opts ...LexerOpt) ([]tokens.Token, error) {
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	return LexBytes(content, opts...), nil
+}
+
+// LexBytes tokenizes the CSS in input without copying it. The literals of the tokens are slices
+// of input, so it must not be modified while the tokens are in use.
+func LexBytes(input []byte, opts ...LexerOpt) []tokens.Token {
+	if newLexerOptions(opts).spec {
+		return lexSpec(input)
+	}
+	return lexDefault(input)
+}
+
+func newLexerOptions(opts []LexerOpt) *lexerOptions {
+	options := &lexerOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+func lexDefault(input []byte) []tokens.Token {
+	l := lexerPool.Get()
+	defer lexerPool.Put(l)
+
+	l.input = input
 	l.readChar()
 
 	return l.tokenize()
 }
 
 func (l *lexer) Erase() {
-	l.input = l.input[:0]
+	// The input belongs to the tokens, so the pooled lexer must not hold on to it
+	l.input = nil
 	l.position = 0
 	l.readPosition = 0
 	l.ch = 0
@@ -152,15 +222,20 @@ func (l *lexer) tokenize() []tokens.Token {
 }
 
 func (l *lexer) nextToken() *tokens.Token {
+	spaceBefore := isWhitespace[l.ch]
 	l.skipWhitespace()
 	tok := tokenPool.Get()
+	tok.SpaceBefore = spaceBefore
 	tok.Line = l.line
 	tok.Column = l.column
 	start := l.position
 
+	tok.Offset = start
+
 	if l.ch == EOF {
 		tok.Type = tokens.EOF
 		tok.Literal = []byte{}
+		tok.End = start
 		return tok
 	}
 
@@ -218,12 +293,12 @@ func (l *lexer) nextToken() *tokens.Token {
 			tok.Type = tokens.COLON
 		}
 	case DOT:
-        if isDigit[l.peekChar()] {
-            tok.Type = tokens.NUMBER
-            l.readNumber()
-        } else {
-            tok.Type = tokens.DOT
-        }
+		if isDigit[l.peekChar()] {
+			tok.Type = tokens.NUMBER
+			l.readNumber()
+		} else {
+			tok.Type = tokens.DOT
+		}
 	case HASH:
 		tok.Type = l.readHashOrColor()
 	case DASH:
@@ -267,6 +342,7 @@ func (l *lexer) nextToken() *tokens.Token {
 
 	end := l.position
 	tok.Literal = l.getLiteral(start, end)
+	tok.End = start + len(tok.Literal)
 
 	return tok
 }
@@ -318,6 +394,14 @@ func (l *lexer) peekNextChar() byte {
 	return l.input[l.readPosition+1]
 }
 
+// peekCharAt returns the character n places after the next one, or 0 past the end of the input.
+func (l *lexer) peekCharAt(n int) byte {
+	if l.readPosition+n >= len(l.input) {
+		return 0
+	}
+	return l.input[l.readPosition+n]
+}
+
 func (l *lexer) handleSlash() tokens.TokenType {
 	if l.peekChar() == '*' {
 		l.readChar() // consume '*'
@@ -370,6 +454,19 @@ func (l *lexer) readNumber() {
 			l.readChar()
 		}
 	}
+	// An exponent is only part of the number when digits follow it, so "2em" keeps its unit
+	if e := l.peekChar(); e == 'e' || e == 'E' {
+		sign := l.peekNextChar() == '+' || l.peekNextChar() == '-'
+		if isDigit[l.peekNextChar()] || (sign && isDigit[l.peekCharAt(2)]) {
+			l.readChar() // consume 'e'
+			if sign {
+				l.readChar()
+			}
+			for isDigit[l.peekChar()] {
+				l.readChar()
+			}
+		}
+	}
 }
 
 func (l *lexer) readIdentifier() {
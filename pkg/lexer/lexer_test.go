@@ -2,10 +2,13 @@ package lexer_test
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/aledsdavies/pristinecss/pkg/lexer"
 	"github.com/aledsdavies/pristinecss/pkg/tokens"
@@ -233,6 +236,32 @@ func TestComplexSelectors(t *testing.T) {
 				{Type: tokens.RBRACE, Literal: []byte("}")},
 			},
 		},
+		{
+			name:  "Numbers with exponents",
+			input: `.a { width: 1e3px; margin: 1.5E-2em 2e+1% 2em 3e; }`,
+			expected: []tokens.Token{
+				{Type: tokens.DOT, Literal: []byte(".")},
+				{Type: tokens.IDENT, Literal: []byte("a")},
+				{Type: tokens.LBRACE, Literal: []byte("{")},
+				{Type: tokens.IDENT, Literal: []byte("width")},
+				{Type: tokens.COLON, Literal: []byte(":")},
+				{Type: tokens.NUMBER, Literal: []byte("1e3")},
+				{Type: tokens.IDENT, Literal: []byte("px")},
+				{Type: tokens.SEMICOLON, Literal: []byte(";")},
+				{Type: tokens.IDENT, Literal: []byte("margin")},
+				{Type: tokens.COLON, Literal: []byte(":")},
+				{Type: tokens.NUMBER, Literal: []byte("1.5E-2")},
+				{Type: tokens.IDENT, Literal: []byte("em")},
+				{Type: tokens.NUMBER, Literal: []byte("2e+1")},
+				{Type: tokens.PERCENTAGE, Literal: []byte("%")},
+				{Type: tokens.NUMBER, Literal: []byte("2")},
+				{Type: tokens.IDENT, Literal: []byte("em")},
+				{Type: tokens.NUMBER, Literal: []byte("3")},
+				{Type: tokens.IDENT, Literal: []byte("e")},
+				{Type: tokens.SEMICOLON, Literal: []byte(";")},
+				{Type: tokens.RBRACE, Literal: []byte("}")},
+			},
+		},
 	}
 
 	runTests(t, tests)
@@ -703,6 +732,9 @@ func runTests(t *testing.T, tests []struct {
 				if tok.Line == 0 || tok.Column == 0 {
 					t.Errorf("Token %d: line or column not set. got line=%d, column=%d", i, tok.Line, tok.Column)
 				}
+				if tok.End > len(tt.input) || tt.input[tok.Offset:tok.End] != string(tok.Literal) {
+					t.Errorf("Token %d: span %d:%d does not match the literal %q", i, tok.Offset, tok.End, tok.Literal)
+				}
 			}
 		})
 	}
@@ -857,6 +889,62 @@ func TestFrameworks(t *testing.T) {
 	}
 }
 
+func TestLexReaderError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	input := io.MultiReader(strings.NewReader("div { color: "), iotest.ErrReader(readErr))
+
+	toks, err := lexer.LexReader(input)
+	if !errors.Is(err, readErr) {
+		t.Fatalf("Expected the read error, got %v", err)
+	}
+	if toks != nil {
+		t.Errorf("Expected no tokens, got %d", len(toks))
+	}
+}
+
+func TestLexBytes(t *testing.T) {
+	input := "@media (min-width: 10px) { .a > b::before { content: \"x\"; } }"
+
+	expected := lexer.Lex(strings.NewReader(input))
+	toks := lexer.LexBytes([]byte(input))
+	if len(toks) != len(expected) {
+		t.Fatalf("Expected %d tokens, got %d", len(expected), len(toks))
+	}
+	for i := range toks {
+		if toks[i].Type != expected[i].Type || !bytesEqual(toks[i].Literal, expected[i].Literal) ||
+			toks[i].Line != expected[i].Line || toks[i].Column != expected[i].Column {
+			t.Errorf("Token %d: expected %v %q, got %v %q", i, expected[i].Type, expected[i].Literal, toks[i].Type, toks[i].Literal)
+		}
+	}
+}
+
+func TestTokensOutliveLaterLex(t *testing.T) {
+	first := lexer.Lex(strings.NewReader(".a { color: red; }"))
+	expected := make([]string, len(first))
+	for i, tok := range first {
+		expected[i] = string(tok.Literal)
+	}
+
+	lexer.Lex(strings.NewReader("#main > .b::before { content: \"overwritten\"; margin: 0 auto; }"))
+
+	for i, tok := range first {
+		if string(tok.Literal) != expected[i] {
+			t.Errorf("Token %d: expected literal %q, got %q", i, expected[i], tok.Literal)
+		}
+	}
+}
+
+func TestSpaceBefore(t *testing.T) {
+	toks := lexer.Lex(strings.NewReader(".nav a.b\n\t> c"))
+
+	expected := []bool{false, false, true, false, false, true, true, false}
+	for i, want := range expected {
+		if toks[i].SpaceBefore != want {
+			t.Errorf("Token %d %q: expected SpaceBefore %v, got %v", i, toks[i].Literal, want, toks[i].SpaceBefore)
+		}
+	}
+}
+
 func BenchmarkFrameworks(b *testing.B) {
 	frameworks := []struct {
 		name string
@@ -0,0 +1,171 @@
+package lexer
+
+import (
+	"errors"
+	"io"
+
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+const (
+	// defaultChunkSize is the number of bytes the scanner reads from its input at a time
+	defaultChunkSize = 64 * 1024
+	// maxLookahead is the number of bytes past the end of a token the lexers may look at to
+	// decide where it ends. A token that ends closer than this to the end of the buffered input
+	// is lexed again once more input has been read.
+	maxLookahead = 16
+)
+
+// Scanner tokenizes CSS read from an io.Reader one token at a time, reading the input in chunks
+// rather than all at once. Literals of the returned tokens are never overwritten by later reads,
+// so tokens remain valid after the next call to Next.
+type Scanner struct {
+	r         io.Reader
+	chunkSize int
+	lexer     streamLexer
+	// base is the offset in the input of the first buffered byte
+	base int
+	done bool
+	err  error
+}
+
+// NewScanner returns a scanner that reads CSS from r.
+//
+// Parameters:
+// - r: The reader the CSS is read from, until EOF.
+// - opts: Options such as WithSpecMode and WithChunkSize.
+//
+// Returns:
+// - A scanner positioned before the first token.
+func NewScanner(r io.Reader, opts ...LexerOpt) *Scanner {
+	options := newLexerOptions(opts)
+	s := &Scanner{
+		r:         r,
+		chunkSize: defaultChunkSize,
+	}
+	if options.chunkSize > 0 {
+		s.chunkSize = options.chunkSize
+	}
+
+	if options.spec {
+		s.lexer = &specLexer{line: 1, column: 1}
+	} else {
+		l := &lexer{}
+		l.Erase()
+		l.readChar()
+		s.lexer = l
+	}
+	return s
+}
+
+// Next returns the next token. Once the input is exhausted, or cannot be read, it returns an
+// EOF token on every call, and Err reports whether reading failed.
+func (s *Scanner) Next() tokens.Token {
+	for {
+		state := s.lexer.checkpoint()
+		if !s.done && len(s.lexer.buffered())-state.offset < maxLookahead {
+			s.refill(state)
+			continue
+		}
+
+		tok := s.lexer.scan()
+		if !s.done && s.lexer.checkpoint().offset+maxLookahead > len(s.lexer.buffered()) {
+			// The token may continue past the buffered input, so it is lexed again with more
+			s.refill(state)
+			continue
+		}
+		tok.Offset += s.base
+		tok.End += s.base
+		return tok
+	}
+}
+
+// Err returns the error that stopped the scanner reading its input, or nil if it reached EOF.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// refill replaces the buffered input with its unlexed tail from the state onwards followed by
+// the next chunk of the input, and rewinds the lexer to the state. The old buffer is left
+// untouched as the literals of earlier tokens point into it.
+func (s *Scanner) refill(state lexState) {
+	tail := s.lexer.buffered()[state.offset:]
+	// Reading at least as much again as is buffered keeps relexing a long token linear
+	size := max(s.chunkSize, len(tail))
+	buf := make([]byte, len(tail)+size)
+	copy(buf, tail)
+
+	n, err := io.ReadFull(s.r, buf[len(tail):])
+	if err != nil {
+		s.done = true
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			s.err = err
+			n = 0
+			tail = nil
+		}
+	}
+
+	s.base += state.offset
+	state.offset = 0
+	s.lexer.reset(buf[:len(tail)+n], state)
+}
+
+// lexState is the position of a lexer between tokens.
+type lexState struct {
+	offset int
+	line   int
+	column int
+}
+
+// streamLexer is a lexer that the Scanner can rewind and feed more input.
+type streamLexer interface {
+	scan() tokens.Token
+	buffered() []byte
+	checkpoint() lexState
+	reset(input []byte, state lexState)
+}
+
+func (l *lexer) scan() tokens.Token {
+	tok := l.nextToken()
+	defer tokenPool.Put(tok)
+	return *tok
+}
+
+func (l *lexer) buffered() []byte {
+	return l.input
+}
+
+func (l *lexer) checkpoint() lexState {
+	return lexState{offset: l.position, line: l.line, column: l.column}
+}
+
+func (l *lexer) reset(input []byte, state lexState) {
+	l.input = input
+	l.position = state.offset
+	l.readPosition = state.offset + 1
+	l.line = state.line
+	l.column = state.column
+	l.ch = EOF
+	if state.offset < len(input) {
+		l.ch = input[state.offset]
+	}
+}
+
+func (l *specLexer) scan() tokens.Token {
+	return l.nextToken()
+}
+
+func (l *specLexer) buffered() []byte {
+	return l.input
+}
+
+func (l *specLexer) checkpoint() lexState {
+	return lexState{offset: l.pos, line: l.line, column: l.column}
+}
+
+func (l *specLexer) reset(input []byte, state lexState) {
+	l.input = input
+	l.pos = state.offset
+	l.line = state.line
+	l.column = state.column
+}
@@ -0,0 +1,83 @@
+package lexer_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+func TestScannerMatchesLex(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "..", "test-data", "frameworks", "bootstrap.css"))
+	if err != nil {
+		t.Fatalf("Could not read the file: %v", err)
+	}
+	inputs := map[string]string{
+		"Bootstrap": string(content),
+		"Long tokens": "/* " + strings.Repeat("comment ", 100) + "*/ .a { background: url(data:image/png;base64," +
+			strings.Repeat("iVBORw0KGgo", 50) + "); content: \"" + strings.Repeat("x", 300) + "\"; }",
+	}
+	modes := map[string][]lexer.LexerOpt{
+		"Default": nil,
+		"Spec":    {lexer.WithSpecMode()},
+	}
+
+	for name, input := range inputs {
+		for mode, opts := range modes {
+			expected := lexer.Lex(strings.NewReader(input), opts...)
+			for _, chunkSize := range []int{1, 7, 100, 4096} {
+				t.Run(name+"/"+mode, func(t *testing.T) {
+					// OneByteReader returns short reads, exercising reads that end mid token
+					s := lexer.NewScanner(iotest.OneByteReader(strings.NewReader(input)), append(opts, lexer.WithChunkSize(chunkSize))...)
+					var got []tokens.Token
+					for {
+						tok := s.Next()
+						got = append(got, tok)
+						if tok.Type == tokens.EOF {
+							break
+						}
+					}
+					if err := s.Err(); err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+
+					if len(got) != len(expected) {
+						t.Fatalf("Chunk size %d: expected %d tokens, got %d", chunkSize, len(expected), len(got))
+					}
+					for i := range got {
+						if got[i].Type != expected[i].Type || !bytesEqual(got[i].Literal, expected[i].Literal) ||
+							got[i].Line != expected[i].Line || got[i].Column != expected[i].Column ||
+							got[i].Offset != expected[i].Offset || got[i].End != expected[i].End {
+							t.Fatalf("Chunk size %d, token %d: expected %v %q at %d:%d, got %v %q at %d:%d", chunkSize, i,
+								expected[i].Type, expected[i].Literal, expected[i].Line, expected[i].Column,
+								got[i].Type, got[i].Literal, got[i].Line, got[i].Column)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestScannerError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	s := lexer.NewScanner(io.MultiReader(strings.NewReader("div { color: red; }"), iotest.ErrReader(readErr)))
+
+	for i := 0; i < 100; i++ {
+		if s.Next().Type == tokens.EOF {
+			break
+		}
+	}
+	if tok := s.Next(); tok.Type != tokens.EOF {
+		t.Errorf("Expected EOF after a read error, got %v", tok.Type)
+	}
+	if !errors.Is(s.Err(), readErr) {
+		t.Errorf("Expected the read error, got %v", s.Err())
+	}
+}
@@ -0,0 +1,480 @@
+package lexer
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+// eof is returned by specLexer.peek past the end of the input, so that a NUL byte in the input
+// is not mistaken for the end.
+const eof = -1
+
+// lexSpec tokenizes the content following the token definitions of CSS Syntax Level 3
+// (https://www.w3.org/TR/css-syntax-3/#tokenization). It differs from the default mode in that:
+//   - whitespace is kept as WHITESPACE tokens
+//   - a hash is always a HASH with its Flag set to FlagID or FlagUnrestricted, whatever its length
+//   - numbers accept a sign, a fraction and an exponent, and are NUMBER, PERCENTAGE or DIMENSION
+//     tokens with their Flag set to FlagInteger or FlagNumber, and the Unit of a DIMENSION set
+//   - identifiers followed by '(' are FUNCTION tokens, and '@' followed by an identifier is an
+//     AT_KEYWORD token
+//   - unquoted url(...) is a URI token, or BAD_URL if it is malformed, while url( followed by a
+//     quote is a FUNCTION token
+//   - a string ended by a newline is a BAD_STRING token
+//   - UNICODE_RANGE, CDO, CDC, COLUMN and the attribute matchers ~=, |=, ^=, $= and *= are tokens
+//   - any other code point is a DELIM token
+//
+// Comments are kept as COMMENT tokens, rather than dropped as the specification describes, so
+// that tools built on the tokens can preserve them.
+//
+// Literals are the raw bytes of each token in the content, escapes included, and lines and
+// columns are 1-based with \r\n, \r and \f counted as newlines.
+func lexSpec(content []byte) []tokens.Token {
+	l := &specLexer{input: content, line: 1, column: 1}
+	result := make([]tokens.Token, 0, estimateTokenCount(len(content)))
+	for {
+		tok := l.nextToken()
+		result = append(result, tok)
+		if tok.Type == tokens.EOF {
+			return result
+		}
+	}
+}
+
+type specLexer struct {
+	input  []byte
+	pos    int
+	line   int
+	column int
+}
+
+func (l *specLexer) nextToken() tokens.Token {
+	tok := tokens.Token{Line: l.line, Column: l.column, Offset: l.pos}
+	start := l.pos
+
+	c := l.peek(0)
+	switch {
+	case c == eof:
+		tok.Type = tokens.EOF
+		tok.Literal = []byte{}
+		tok.End = start
+		return tok
+	case inTable(&isWhitespace, c):
+		for inTable(&isWhitespace, l.peek(0)) {
+			l.advance(1)
+		}
+		tok.Type = tokens.WHITESPACE
+	case c == '/' && l.peek(1) == '*':
+		l.consumeComment()
+		tok.Type = tokens.COMMENT
+	case c == '"' || c == '\'':
+		tok.Type = l.consumeString()
+	case c == '#':
+		if isNameCode(l.peek(1)) || validEscape(l.peek(1), l.peek(2)) {
+			tok.Type = tokens.HASH
+			tok.Flag = tokens.FlagUnrestricted
+			if startsIdentifier(l.peek(1), l.peek(2), l.peek(3)) {
+				tok.Flag = tokens.FlagID
+			}
+			l.advance(1)
+			l.consumeName()
+		} else {
+			tok.Type = l.consumeDelim()
+		}
+	case c == '+' || c == '.':
+		if startsNumber(c, l.peek(1), l.peek(2)) {
+			tok = l.consumeNumeric(tok)
+		} else {
+			tok.Type = l.consumeDelim()
+		}
+	case c == '-':
+		if startsNumber(c, l.peek(1), l.peek(2)) {
+			tok = l.consumeNumeric(tok)
+		} else if l.peek(1) == '-' && l.peek(2) == '>' {
+			l.advance(3)
+			tok.Type = tokens.CDC
+		} else if startsIdentifier(c, l.peek(1), l.peek(2)) {
+			tok.Type = l.consumeIdentLike()
+		} else {
+			tok.Type = l.consumeDelim()
+		}
+	case c == '<':
+		if l.peek(1) == '!' && l.peek(2) == '-' && l.peek(3) == '-' {
+			l.advance(4)
+			tok.Type = tokens.CDO
+		} else {
+			tok.Type = l.consumeDelim()
+		}
+	case c == '@':
+		if startsIdentifier(l.peek(1), l.peek(2), l.peek(3)) {
+			l.advance(1)
+			l.consumeName()
+			tok.Type = tokens.AT_KEYWORD
+		} else {
+			tok.Type = l.consumeDelim()
+		}
+	case c == '\\':
+		if validEscape(c, l.peek(1)) {
+			tok.Type = l.consumeIdentLike()
+		} else {
+			tok.Type = l.consumeDelim()
+		}
+	case inTable(&isDigit, c):
+		tok = l.consumeNumeric(tok)
+	case (c == 'u' || c == 'U') && l.peek(1) == '+' && (inTable(&isHexDigit, l.peek(2)) || l.peek(2) == '?'):
+		l.consumeUnicodeRange()
+		tok.Type = tokens.UNICODE_RANGE
+	case isNameStartCode(c):
+		tok.Type = l.consumeIdentLike()
+	case c == '|' && l.peek(1) == '|':
+		l.advance(2)
+		tok.Type = tokens.COLUMN
+	case l.peek(1) == '=' && matchTokens[c] != "":
+		l.advance(2)
+		tok.Type = matchTokens[c]
+	case punctuationTokens[c] != "":
+		l.advance(1)
+		tok.Type = punctuationTokens[c]
+	default:
+		tok.Type = l.consumeDelim()
+	}
+
+	tok.Literal = l.input[start:l.pos]
+	tok.End = l.pos
+	return tok
+}
+
+// matchTokens are the tokens of a code point followed by '='.
+var matchTokens = [256]tokens.TokenType{
+	'~': tokens.INCLUDE_MATCH,
+	'|': tokens.DASH_MATCH,
+	'^': tokens.STARTS_WITH,
+	'$': tokens.SUFFIX_MATCH,
+	'*': tokens.SUBSTRING_MATCH,
+}
+
+var punctuationTokens = [256]tokens.TokenType{
+	'(': tokens.LPAREN,
+	')': tokens.RPAREN,
+	'[': tokens.LBRACKET,
+	']': tokens.RBRACKET,
+	'{': tokens.LBRACE,
+	'}': tokens.RBRACE,
+	',': tokens.COMMA,
+	':': tokens.COLON,
+	';': tokens.SEMICOLON,
+}
+
+// peek returns the byte n bytes ahead of the current position, or eof past the end of the input.
+func (l *specLexer) peek(n int) int {
+	if l.pos+n >= len(l.input) {
+		return eof
+	}
+	return int(l.input[l.pos+n])
+}
+
+// advance consumes n bytes, tracking the line and column of the next byte.
+func (l *specLexer) advance(n int) {
+	for ; n > 0 && l.pos < len(l.input); n-- {
+		ch := l.input[l.pos]
+		l.pos++
+		if ch == '\n' || ch == '\f' || (ch == '\r' && l.peek(0) != '\n') {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
+	}
+}
+
+// consumeCodePoint consumes a whole UTF-8 encoded code point.
+func (l *specLexer) consumeCodePoint() {
+	_, size := utf8.DecodeRune(l.input[l.pos:])
+	l.advance(size)
+}
+
+// consumeNewline consumes a newline, treating \r\n as one.
+func (l *specLexer) consumeNewline() {
+	if l.peek(0) == '\r' && l.peek(1) == '\n' {
+		l.advance(2)
+		return
+	}
+	l.advance(1)
+}
+
+func (l *specLexer) consumeDelim() tokens.TokenType {
+	l.consumeCodePoint()
+	return tokens.DELIM
+}
+
+func (l *specLexer) consumeComment() {
+	end := bytes.Index(l.input[l.pos+2:], []byte("*/"))
+	if end < 0 {
+		l.advance(len(l.input) - l.pos)
+		return
+	}
+	l.advance(end + 4)
+}
+
+// consumeString consumes a quoted string. A string ended by a newline is a BAD_STRING, and the
+// newline is left to be consumed as whitespace.
+func (l *specLexer) consumeString() tokens.TokenType {
+	quote := l.peek(0)
+	l.advance(1)
+	for {
+		switch c := l.peek(0); {
+		case c == quote:
+			l.advance(1)
+			return tokens.STRING
+		case c == eof:
+			return tokens.STRING
+		case isNewline(c):
+			return tokens.BAD_STRING
+		case c == '\\':
+			l.advance(1)
+			if isNewline(l.peek(0)) {
+				l.consumeNewline()
+			} else if l.peek(0) != eof {
+				l.consumeEscape()
+			}
+		default:
+			l.consumeCodePoint()
+		}
+	}
+}
+
+// consumeEscape consumes an escape after its backslash: up to six hex digits followed by an
+// optional whitespace, or any single code point.
+func (l *specLexer) consumeEscape() {
+	if !inTable(&isHexDigit, l.peek(0)) {
+		if l.peek(0) != eof {
+			l.consumeCodePoint()
+		}
+		return
+	}
+	for i := 0; i < 6 && inTable(&isHexDigit, l.peek(0)); i++ {
+		l.advance(1)
+	}
+	if isNewline(l.peek(0)) {
+		l.consumeNewline()
+	} else if inTable(&isWhitespace, l.peek(0)) {
+		l.advance(1)
+	}
+}
+
+func (l *specLexer) consumeName() {
+	for {
+		if isNameCode(l.peek(0)) {
+			l.advance(1)
+		} else if validEscape(l.peek(0), l.peek(1)) {
+			l.advance(1)
+			l.consumeEscape()
+		} else {
+			return
+		}
+	}
+}
+
+// consumeNumeric consumes a NUMBER, PERCENTAGE or DIMENSION token.
+func (l *specLexer) consumeNumeric(tok tokens.Token) tokens.Token {
+	tok.Flag = tokens.FlagInteger
+	if l.peek(0) == '+' || l.peek(0) == '-' {
+		l.advance(1)
+	}
+	l.consumeDigits()
+	if l.peek(0) == '.' && inTable(&isDigit, l.peek(1)) {
+		tok.Flag = tokens.FlagNumber
+		l.advance(1)
+		l.consumeDigits()
+	}
+	if e := l.peek(0); e == 'e' || e == 'E' {
+		if inTable(&isDigit, l.peek(1)) {
+			tok.Flag = tokens.FlagNumber
+			l.advance(1)
+			l.consumeDigits()
+		} else if (l.peek(1) == '+' || l.peek(1) == '-') && inTable(&isDigit, l.peek(2)) {
+			tok.Flag = tokens.FlagNumber
+			l.advance(2)
+			l.consumeDigits()
+		}
+	}
+
+	switch {
+	case startsIdentifier(l.peek(0), l.peek(1), l.peek(2)):
+		unitStart := l.pos
+		l.consumeName()
+		tok.Type = tokens.DIMENSION
+		tok.Unit = l.input[unitStart:l.pos]
+	case l.peek(0) == '%':
+		l.advance(1)
+		tok.Type = tokens.PERCENTAGE
+	default:
+		tok.Type = tokens.NUMBER
+	}
+	return tok
+}
+
+func (l *specLexer) consumeDigits() {
+	for inTable(&isDigit, l.peek(0)) {
+		l.advance(1)
+	}
+}
+
+// consumeIdentLike consumes an IDENT, FUNCTION, URI or BAD_URL token.
+func (l *specLexer) consumeIdentLike() tokens.TokenType {
+	start := l.pos
+	l.consumeName()
+	if l.peek(0) != '(' {
+		return tokens.IDENT
+	}
+
+	isURL := bytes.EqualFold(l.input[start:l.pos], []byte("url"))
+	l.advance(1)
+	if !isURL {
+		return tokens.FUNCTION
+	}
+
+	next := l.pos
+	for next < len(l.input) && isWhitespace[l.input[next]] {
+		next++
+	}
+	if next < len(l.input) && (l.input[next] == '"' || l.input[next] == '\'') {
+		// A quoted url is a function taking a string
+		return tokens.FUNCTION
+	}
+	return l.consumeURL()
+}
+
+// consumeURL consumes the rest of an unquoted url(...) after its '('.
+func (l *specLexer) consumeURL() tokens.TokenType {
+	l.consumeWhitespace()
+	for {
+		c := l.peek(0)
+		switch {
+		case c == ')':
+			l.advance(1)
+			return tokens.URI
+		case c == eof:
+			return tokens.URI
+		case inTable(&isWhitespace, c):
+			l.consumeWhitespace()
+			if l.peek(0) == ')' || l.peek(0) == eof {
+				l.advance(1)
+				return tokens.URI
+			}
+			l.consumeBadURLRemnants()
+			return tokens.BAD_URL
+		case c == '"' || c == '\'' || c == '(' || isNonPrintable(c):
+			l.consumeBadURLRemnants()
+			return tokens.BAD_URL
+		case c == '\\':
+			if !validEscape(c, l.peek(1)) {
+				l.consumeBadURLRemnants()
+				return tokens.BAD_URL
+			}
+			l.advance(1)
+			l.consumeEscape()
+		default:
+			l.consumeCodePoint()
+		}
+	}
+}
+
+// consumeBadURLRemnants consumes the rest of a malformed url(...), up to and including its ')'.
+func (l *specLexer) consumeBadURLRemnants() {
+	for {
+		c := l.peek(0)
+		switch {
+		case c == ')' || c == eof:
+			l.advance(1)
+			return
+		case validEscape(c, l.peek(1)):
+			l.advance(1)
+			l.consumeEscape()
+		default:
+			l.consumeCodePoint()
+		}
+	}
+}
+
+// consumeUnicodeRange consumes a unicode-range such as U+26, U+0-7F or U+4??.
+func (l *specLexer) consumeUnicodeRange() {
+	l.advance(2) // Consume 'u+'
+	digits := 0
+	for digits < 6 && inTable(&isHexDigit, l.peek(0)) {
+		l.advance(1)
+		digits++
+	}
+	wildcard := false
+	for digits < 6 && l.peek(0) == '?' {
+		l.advance(1)
+		digits++
+		wildcard = true
+	}
+	if wildcard {
+		return
+	}
+	if l.peek(0) == '-' && inTable(&isHexDigit, l.peek(1)) {
+		l.advance(1)
+		for digits = 0; digits < 6 && inTable(&isHexDigit, l.peek(0)); digits++ {
+			l.advance(1)
+		}
+	}
+}
+
+func (l *specLexer) consumeWhitespace() {
+	for inTable(&isWhitespace, l.peek(0)) {
+		l.advance(1)
+	}
+}
+
+// inTable looks the byte up in one of the character tables, which never contain eof.
+func inTable(table *[256]bool, c int) bool {
+	return c != eof && table[c]
+}
+
+func isNewline(c int) bool {
+	return c == '\n' || c == '\r' || c == '\f'
+}
+
+func isNameStartCode(c int) bool {
+	return inTable(&isLetter, c)
+}
+
+func isNameCode(c int) bool {
+	return inTable(&isIdentPart, c)
+}
+
+func isNonPrintable(c int) bool {
+	return (c >= 0 && c <= 0x08) || c == 0x0B || (c >= 0x0E && c <= 0x1F) || c == 0x7F
+}
+
+// validEscape reports whether the two code points start a valid escape.
+func validEscape(c1, c2 int) bool {
+	return c1 == '\\' && !isNewline(c2)
+}
+
+// startsIdentifier reports whether the three code points would start an identifier.
+func startsIdentifier(c1, c2, c3 int) bool {
+	switch {
+	case c1 == '-':
+		return isNameStartCode(c2) || c2 == '-' || validEscape(c2, c3)
+	case c1 == '\\':
+		return validEscape(c1, c2)
+	default:
+		return isNameStartCode(c1)
+	}
+}
+
+// startsNumber reports whether the three code points would start a number.
+func startsNumber(c1, c2, c3 int) bool {
+	switch {
+	case c1 == '+' || c1 == '-':
+		return inTable(&isDigit, c2) || (c2 == '.' && inTable(&isDigit, c3))
+	case c1 == '.':
+		return inTable(&isDigit, c2)
+	default:
+		return inTable(&isDigit, c1)
+	}
+}
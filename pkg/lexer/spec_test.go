@@ -0,0 +1,211 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+func TestSpecModeTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []tokens.Token
+	}{
+		{
+			name:  "Hashes of any length",
+			input: "#abc #abcd #aabbccdd #123",
+			expected: []tokens.Token{
+				{Type: tokens.HASH, Literal: []byte("#abc"), Flag: tokens.FlagID},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.HASH, Literal: []byte("#abcd"), Flag: tokens.FlagID},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.HASH, Literal: []byte("#aabbccdd"), Flag: tokens.FlagID},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.HASH, Literal: []byte("#123"), Flag: tokens.FlagUnrestricted},
+			},
+		},
+		{
+			name:  "Numbers with signs, fractions and exponents",
+			input: "12 -3.5 +.5 1e3 2.5E-2 1e",
+			expected: []tokens.Token{
+				{Type: tokens.NUMBER, Literal: []byte("12"), Flag: tokens.FlagInteger},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.NUMBER, Literal: []byte("-3.5"), Flag: tokens.FlagNumber},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.NUMBER, Literal: []byte("+.5"), Flag: tokens.FlagNumber},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.NUMBER, Literal: []byte("1e3"), Flag: tokens.FlagNumber},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.NUMBER, Literal: []byte("2.5E-2"), Flag: tokens.FlagNumber},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.DIMENSION, Literal: []byte("1e"), Unit: []byte("e"), Flag: tokens.FlagInteger},
+			},
+		},
+		{
+			name:  "Dimensions and percentages",
+			input: "10px 1.5em 50% -2\\31 x",
+			expected: []tokens.Token{
+				{Type: tokens.DIMENSION, Literal: []byte("10px"), Unit: []byte("px"), Flag: tokens.FlagInteger},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.DIMENSION, Literal: []byte("1.5em"), Unit: []byte("em"), Flag: tokens.FlagNumber},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.PERCENTAGE, Literal: []byte("50%"), Flag: tokens.FlagInteger},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.DIMENSION, Literal: []byte("-2\\31 x"), Unit: []byte("\\31 x"), Flag: tokens.FlagInteger},
+			},
+		},
+		{
+			name:  "Attribute matchers and column",
+			input: "~= |= ^= $= *= || | =",
+			expected: []tokens.Token{
+				{Type: tokens.INCLUDE_MATCH, Literal: []byte("~=")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.DASH_MATCH, Literal: []byte("|=")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.STARTS_WITH, Literal: []byte("^=")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.SUFFIX_MATCH, Literal: []byte("$=")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.SUBSTRING_MATCH, Literal: []byte("*=")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.COLUMN, Literal: []byte("||")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.DELIM, Literal: []byte("|")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.DELIM, Literal: []byte("=")},
+			},
+		},
+		{
+			name:  "Unicode ranges",
+			input: "U+26 u+0-7F U+4??",
+			expected: []tokens.Token{
+				{Type: tokens.UNICODE_RANGE, Literal: []byte("U+26")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.UNICODE_RANGE, Literal: []byte("u+0-7F")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.UNICODE_RANGE, Literal: []byte("U+4??")},
+			},
+		},
+		{
+			name:  "CDO and CDC",
+			input: "<!-- --> <",
+			expected: []tokens.Token{
+				{Type: tokens.CDO, Literal: []byte("<!--")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.CDC, Literal: []byte("-->")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.DELIM, Literal: []byte("<")},
+			},
+		},
+		{
+			name:  "Strings and bad strings",
+			input: "'a\\'b' \"open\n",
+			expected: []tokens.Token{
+				{Type: tokens.STRING, Literal: []byte("'a\\'b'")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.BAD_STRING, Literal: []byte("\"open")},
+				{Type: tokens.WHITESPACE, Literal: []byte("\n")},
+			},
+		},
+		{
+			name:  "URLs and bad URLs",
+			input: "url( a.png ) url(\"b.png\") url(a b) url(a\"b)",
+			expected: []tokens.Token{
+				{Type: tokens.URI, Literal: []byte("url( a.png )")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.FUNCTION, Literal: []byte("url(")},
+				{Type: tokens.STRING, Literal: []byte("\"b.png\"")},
+				{Type: tokens.RPAREN, Literal: []byte(")")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.BAD_URL, Literal: []byte("url(a b)")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.BAD_URL, Literal: []byte("url(a\"b)")},
+			},
+		},
+		{
+			name:  "At-keywords, functions and delimiters",
+			input: "@media rgb(0) @ -- -x !",
+			expected: []tokens.Token{
+				{Type: tokens.AT_KEYWORD, Literal: []byte("@media")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.FUNCTION, Literal: []byte("rgb(")},
+				{Type: tokens.NUMBER, Literal: []byte("0"), Flag: tokens.FlagInteger},
+				{Type: tokens.RPAREN, Literal: []byte(")")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.DELIM, Literal: []byte("@")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.IDENT, Literal: []byte("--")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.IDENT, Literal: []byte("-x")},
+				{Type: tokens.WHITESPACE, Literal: []byte(" ")},
+				{Type: tokens.DELIM, Literal: []byte("!")},
+			},
+		},
+		{
+			name:  "Comments",
+			input: "a/* b */c",
+			expected: []tokens.Token{
+				{Type: tokens.IDENT, Literal: []byte("a")},
+				{Type: tokens.COMMENT, Literal: []byte("/* b */")},
+				{Type: tokens.IDENT, Literal: []byte("c")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toks := lexer.Lex(strings.NewReader(tt.input), lexer.WithSpecMode())
+			if len(toks)-1 != len(tt.expected) {
+				t.Fatalf("Token count mismatch. Expected %d tokens, got %d %v", len(tt.expected), len(toks)-1, literals(toks))
+			}
+			if toks[len(toks)-1].Type != tokens.EOF {
+				t.Errorf("Expected the last token to be EOF, got %v", toks[len(toks)-1].Type)
+			}
+
+			for i, expected := range tt.expected {
+				tok := toks[i]
+				if tok.Type != expected.Type {
+					t.Errorf("Token %d: expected type %v, got %v", i, expected.Type, tok.Type)
+				}
+				if !bytesEqual(tok.Literal, expected.Literal) {
+					t.Errorf("Token %d: expected literal %q, got %q", i, expected.Literal, tok.Literal)
+				}
+				if !bytesEqual(tok.Unit, expected.Unit) {
+					t.Errorf("Token %d: expected unit %q, got %q", i, expected.Unit, tok.Unit)
+				}
+				if tok.Flag != expected.Flag {
+					t.Errorf("Token %d: expected flag %v, got %v", i, expected.Flag, tok.Flag)
+				}
+				if tt.input[tok.Offset:tok.End] != string(tok.Literal) {
+					t.Errorf("Token %d: span %d:%d does not match the literal %q", i, tok.Offset, tok.End, tok.Literal)
+				}
+			}
+		})
+	}
+}
+
+func TestSpecModePositions(t *testing.T) {
+	toks := lexer.Lex(strings.NewReader("a {\r\n  b: 1\fc\rd"), lexer.WithSpecMode())
+
+	expected := map[string][2]int{"a": {1, 1}, "{": {1, 3}, "b": {2, 3}, "1": {2, 6}, "c": {3, 1}, "d": {4, 1}}
+	for _, tok := range toks {
+		want, ok := expected[string(tok.Literal)]
+		if !ok {
+			continue
+		}
+		if tok.Line != want[0] || tok.Column != want[1] {
+			t.Errorf("%q: expected %d:%d, got %d:%d", tok.Literal, want[0], want[1], tok.Line, tok.Column)
+		}
+	}
+}
+
+func literals(toks []tokens.Token) []string {
+	strs := make([]string, len(toks))
+	for i, tok := range toks {
+		strs[i] = string(tok.Literal)
+	}
+	return strs
+}
@@ -0,0 +1,316 @@
+package minify
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+	"github.com/aledsdavies/pristinecss/pkg/printer"
+)
+
+// lengthUnits are the units that can be dropped from a zero length.
+var lengthUnits = map[string]bool{
+	"cm": true, "mm": true, "in": true, "px": true, "pt": true, "pc": true, "Q": true,
+	"em": true, "ex": true, "ch": true, "rem": true, "lh": true, "rlh": true, "vb": true, "vi": true,
+	"vw": true, "vh": true, "vmin": true, "vmax": true,
+	"svw": true, "svh": true, "lvw": true, "lvh": true, "dvw": true, "dvh": true,
+	"cqw": true, "cqh": true, "cqi": true, "cqb": true, "cqmin": true, "cqmax": true,
+}
+
+// numberProperties are the properties in which a unitless zero is read as a <number> rather than
+// a <length>, such as flex-grow in the flex shorthand, so the unit of a zero length must be kept.
+var numberProperties = map[string]bool{
+	"flex": true, "-webkit-flex": true, "-ms-flex": true,
+}
+
+// Minify reads CSS from r and writes the smallest equivalent CSS to w.
+//
+// Top-level rules that the parser does not support, such as @page, or that it reports errors in,
+// are written verbatim, as they may still be valid CSS. The rules between them are minified
+// separately, so that no rule is merged across one that is passed through.
+//
+// Parameters:
+// - w: The writer the minified CSS is written to.
+// - r: The reader the CSS is read from.
+//
+// Returns:
+// - An error if the CSS could not be read or written.
+func Minify(w io.Writer, r io.Reader) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	stylesheet, parseErrors := parser.Parse(lexer.LexBytes(content))
+
+	var out bytes.Buffer
+	var run []parser.Node
+	// rawStart and rawEnd delimit the source being passed through, if rawEnd > rawStart
+	rawStart, rawEnd := 0, 0
+	flush := func() error {
+		if raw := bytes.TrimSpace(content[rawStart:rawEnd]); len(raw) > 0 {
+			out.Write(raw)
+		} else if len(run) > 0 {
+			if err := Fprint(&out, &parser.Stylesheet{Rules: run}); err != nil {
+				return err
+			}
+		}
+		run = nil
+		return nil
+	}
+
+	var rules []parser.Node
+	for _, rule := range stylesheet.Rules {
+		if _, ok := rule.(parser.Positioned); ok {
+			rules = append(rules, rule)
+		}
+	}
+
+	end := 0
+	for i, rule := range rules {
+		pos := rule.(parser.Positioned).Pos()
+		// The parser can report the errors of a rule after its last token, such as at the EOF
+		// after trailing whitespace, so errors up to the next rule are counted against it. Errors
+		// in source skipped before the next rule belong to that source, which is passed through.
+		next, limit := len(content), len(content)+1 // Include errors at the EOF
+		if i+1 < len(rules) {
+			next = rules[i+1].(parser.Positioned).Pos().Offset
+			limit = next
+		}
+		if len(bytes.TrimSpace(content[pos.End:next])) > 0 {
+			limit = pos.End + 1
+		}
+		skipped := len(bytes.TrimSpace(content[end:pos.Offset])) > 0
+		if skipped || hasError(parseErrors, pos.Offset, limit) || emptySelector(rule) {
+			if rawEnd <= rawStart {
+				if err := flush(); err != nil {
+					return err
+				}
+				rawStart = end
+			}
+			rawEnd = pos.End
+		} else {
+			if rawEnd > rawStart {
+				if err := flush(); err != nil {
+					return err
+				}
+				rawStart, rawEnd = 0, 0
+			}
+			run = append(run, rule)
+		}
+		end = pos.End
+	}
+	if len(bytes.TrimSpace(content[end:])) > 0 {
+		if rawEnd <= rawStart {
+			if err := flush(); err != nil {
+				return err
+			}
+			rawStart = end
+		}
+		rawEnd = len(content)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// hasError reports whether any of the errors is at an offset from start up to, but not
+// including, limit.
+func hasError(errs []parser.ParseError, start, limit int) bool {
+	for _, err := range errs {
+		if err.Token.Offset >= start && err.Token.Offset < limit {
+			return true
+		}
+	}
+	return false
+}
+
+// emptySelector reports whether the rule is a style rule whose selectors could not be parsed,
+// which would otherwise be dropped as matching nothing.
+func emptySelector(rule parser.Node) bool {
+	s, ok := rule.(*parser.Selector)
+	return ok && len(s.Selectors) == 0
+}
+
+// Fprint minifies and optimizes the stylesheet in place and writes it to w.
+//
+// Parameters:
+// - w: The writer the minified CSS is written to.
+// - s: The stylesheet to minify.
+// - opts: Additional printer options, such as printer.WithSourceMap.
+//
+// Returns:
+// - An error if the stylesheet could not be printed or written.
+func Fprint(w io.Writer, s *parser.Stylesheet, opts ...printer.PrinterOpt) error {
+	Stylesheet(s)
+	Optimize(s)
+	opts = append([]printer.PrinterOpt{printer.WithCompact(), printer.WithoutLastSemicolon()}, opts...)
+	return printer.Fprint(w, s, opts...)
+}
+
+// Stylesheet minifies the stylesheet in place. It removes comments other than /*! preserved
+// comments */, shortens hex colors, drops the units of zero lengths and removes empty rules.
+func Stylesheet(s *parser.Stylesheet) {
+	s.Rules = minifyRules(s.Rules)
+}
+
+func minifyRules(rules []parser.Node) []parser.Node {
+	kept := rules[:0]
+	for _, rule := range rules {
+		switch r := rule.(type) {
+		case *parser.Comment:
+			if !isPreserved(r) {
+				continue
+			}
+		case *parser.Declaration:
+			minifyDeclaration(r)
+		case *parser.Selector:
+			r.Rules = minifyRules(r.Rules)
+			if isEmpty(r.Rules) {
+				continue
+			}
+		case *parser.MediaAtRule:
+			r.Rules = minifyRules(r.Rules)
+			if isEmpty(r.Rules) {
+				continue
+			}
+		case *parser.ContainerAtRule:
+			r.Declarations = minifyRules(r.Declarations)
+			if isEmpty(r.Declarations) {
+				continue
+			}
+		case *parser.SupportsAtRule:
+			r.Rules = minifyRules(r.Rules)
+			if isEmpty(r.Rules) {
+				continue
+			}
+		case *parser.LayerAtRule:
+			// Empty layers are kept, as they still declare the order of layers
+			r.Rules = minifyRules(r.Rules)
+		case *parser.KeyframesAtRule:
+			for i := range r.Stops {
+				r.Stops[i].Rules = minifyRules(r.Stops[i].Rules)
+			}
+		case *parser.FontFaceAtRule:
+			if len(r.Declarations) == 0 {
+				continue
+			}
+			minifyDeclarations(r.Declarations)
+		case *parser.CounterStyleAtRule:
+			minifyDeclarations(r.Declarations)
+		case *parser.ColorProfileAtRule:
+			minifyDeclarations(r.Declarations)
+		case *parser.FontFeatureValuesAtRule:
+			for i := range r.Blocks {
+				minifyDeclarations(r.Blocks[i].Declarations)
+			}
+		}
+		kept = append(kept, rule)
+	}
+	return kept
+}
+
+// isEmpty reports whether a block has no rules other than preserved comments.
+func isEmpty(rules []parser.Node) bool {
+	for _, rule := range rules {
+		if _, ok := rule.(*parser.Comment); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isPreserved reports whether a comment is a /*! preserved comment */, usually a license.
+func isPreserved(c *parser.Comment) bool {
+	return bytes.HasPrefix(c.Text, []byte("/*!"))
+}
+
+func minifyDeclarations(declarations []parser.Declaration) {
+	for i := range declarations {
+		minifyDeclaration(&declarations[i])
+	}
+}
+
+func minifyDeclaration(d *parser.Declaration) {
+	// Custom properties are substituted verbatim, often into calc(), where a unitless zero is invalid
+	customProperty := bytes.HasPrefix(d.Key, []byte("--"))
+	keepUnits := customProperty || numberProperties[string(bytes.ToLower(d.Key))]
+
+	values := d.Value[:0]
+	for _, value := range d.Value {
+		if _, ok := value.(*parser.Comment); ok {
+			continue
+		}
+		if bv, ok := value.(*parser.BasicValue); ok && !keepUnits {
+			bv.Value = dropZeroUnit(bv.Value)
+		}
+		shortenColors(value)
+		values = append(values, value)
+	}
+	d.Value = values
+}
+
+// shortenColors shortens the hex colors in the value and any function arguments.
+func shortenColors(value parser.Value) {
+	switch v := value.(type) {
+	case *parser.BasicValue:
+		v.Value = shortenColor(v.Value)
+	case *parser.FunctionValue:
+		args := v.Arguments[:0]
+		for _, arg := range v.Arguments {
+			if _, ok := arg.(*parser.Comment); ok {
+				continue
+			}
+			shortenColors(arg)
+			args = append(args, arg)
+		}
+		v.Arguments = args
+	}
+}
+
+// shortenColor lowercases a hex color and shortens #aabbcc to #abc.
+func shortenColor(value []byte) []byte {
+	if len(value) != 4 && len(value) != 7 || value[0] != '#' {
+		return value
+	}
+	for _, ch := range value[1:] {
+		if !isHexDigit(ch) {
+			return value
+		}
+	}
+
+	color := bytes.ToLower(value)
+	if len(color) == 7 && color[1] == color[2] && color[3] == color[4] && color[5] == color[6] {
+		return []byte{'#', color[1], color[3], color[5]}
+	}
+	return color
+}
+
+// dropZeroUnit returns 0 for a zero length such as 0px or 0.0em.
+func dropZeroUnit(value []byte) []byte {
+	end := 0
+	for end < len(value) && (isDigit(value[end]) || value[end] == '.' || (end == 0 && (value[end] == '-' || value[end] == '+'))) {
+		end++
+	}
+	if end == 0 || end == len(value) || !lengthUnits[string(value[end:])] {
+		return value
+	}
+
+	number, err := strconv.ParseFloat(string(value[:end]), 64)
+	if err != nil || number != 0 {
+		return value
+	}
+	return []byte("0")
+}
+
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F')
+}
@@ -0,0 +1,146 @@
+package minify_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aledsdavies/pristinecss/pkg/minify"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Collapses whitespace and drops the last semicolon",
+			input:    "div > p ,  a:hover {\n  color : blue ;\n  margin: 0 auto;\n}",
+			expected: "div>p,a:hover{color:blue;margin:0 auto}",
+		},
+		{
+			name:     "Strips comments but keeps preserved comments",
+			input:    "/*! license */\n/* header */\n.a { /* inside */ color: red; /* after */ }",
+			expected: "/*! license */.a{color:red}",
+		},
+		{
+			name:     "Shortens colors",
+			input:    ".a { color: #FFFFFF; background: linear-gradient(#aabbcc, #ABCDEF); border-color: #AbC; }",
+			expected: ".a{color:#fff;background:linear-gradient(#abc,#abcdef);border-color:#abc}",
+		},
+		{
+			name:     "Drops units on zero lengths",
+			input:    ".a { margin: 0px 0.0em -0rem 10px; transition: opacity 0s; flex-basis: 0%; width: calc(0px + 1em); --gap: 0px; }",
+			expected: ".a{margin:0 0 0 10px;transition:opacity 0s;flex-basis:0%;width:calc(0px + 1em);--gap:0px}",
+		},
+		{
+			name:     "Keeps units on zero lengths where a number means something else",
+			input:    ".a { flex: 1 1 0px; -webkit-flex: 0px; flex-basis: 0px; }",
+			expected: ".a{flex:1 1 0px;-webkit-flex:0px;flex-basis:0}",
+		},
+		{
+			name:     "Drops empty rules",
+			input:    ".a {} .b { /* only a comment */ } @media print { .c {} } .d { color: red; }",
+			expected: ".d{color:red}",
+		},
+		{
+			name:     "Drops empty nested rules",
+			input:    ".a { color: #ff0000; &:hover {} @media print { .b { /* comment */ } } }",
+			expected: ".a{color:#f00}",
+		},
+		{
+			name:     "Keeps at-rules",
+			input:    "@import url(\"a.css\") screen;\n@media (min-width: 768px) {\n  .a { color: #ff0000; }\n}\n@keyframes spin { from { opacity: 0px; } to { opacity: 1; } }",
+			expected: "@import url(\"a.css\") screen;@media (min-width:768px){.a{color:#f00}}@keyframes spin{from{opacity:0}to{opacity:1}}",
+		},
+		{
+			name:     "Passes unsupported at-rules through",
+			input:    ".a { color: #ff0000; }\n@page :first { margin: 1cm; }\n.a { margin: 0px; }",
+			expected: ".a{color:#f00}@page :first { margin: 1cm; }.a{margin:0}",
+		},
+		{
+			name:     "Passes unsupported declarations through",
+			input:    "@font-face { font-family: x; unicode-range: U+0000-00FF, U+0131; }\n.a { color: #ff0000; }",
+			expected: "@font-face { font-family: x; unicode-range: U+0000-00FF, U+0131; }.a{color:#f00}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := minify.Minify(&out, strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, out.String()); diff != "" {
+				t.Errorf("Output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMinifyParseError(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Passes skipped source through",
+			input:    ".a { color: #ff0000; } } .b { color: red",
+			expected: ".a{color:#f00}} .b { color: red",
+		},
+		{
+			name:     "Passes a rule through when its error is reported after trailing whitespace",
+			input:    "[x=\"it's \\\"q\\\"\"] { color: red }\n",
+			expected: "[x=\"it's \\\"q\\\"\"] { color: red }",
+		},
+		{
+			name:     "Keeps rules before an error reported after trailing whitespace",
+			input:    ".a { color: #ff0000; }\n.b { color: red\n\n",
+			expected: ".a{color:#f00}.b { color: red",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := minify.Minify(&out, strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, out.String()); diff != "" {
+				t.Errorf("Output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMinifyFramework(t *testing.T) {
+	path := filepath.Join("..", "..", "test-data", "frameworks", "bootstrap.css")
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Could not open the file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var out bytes.Buffer
+	if err := minify.Minify(&out, file); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out.Len() == 0 {
+		t.Errorf("Expected %s to be minified", path)
+	}
+}
+
+func TestMinifyReadError(t *testing.T) {
+	var out bytes.Buffer
+	readErr := errors.New("connection reset")
+	if err := minify.Minify(&out, iotest.ErrReader(readErr)); !errors.Is(err, readErr) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
@@ -0,0 +1,313 @@
+package minify
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+	"github.com/aledsdavies/pristinecss/pkg/printer"
+)
+
+// safePseudos are the pseudo-classes and pseudo-elements supported widely enough that a
+// selector using them can be merged into a selector list. A list is dropped entirely by a
+// browser that does not understand one of its selectors.
+var safePseudos = map[string]bool{
+	"hover": true, "active": true, "focus": true, "visited": true, "link": true,
+	"first-child": true, "last-child": true, "only-child": true,
+	"first-of-type": true, "last-of-type": true, "only-of-type": true,
+	"nth-child": true, "nth-last-child": true, "nth-of-type": true, "nth-last-of-type": true,
+	"not": true, "checked": true, "disabled": true, "enabled": true, "empty": true,
+	"root": true, "target": true,
+	"before": true, "after": true, "first-line": true, "first-letter": true,
+}
+
+// supportedKeywords are the keywords that every browser supports for the properties that
+// accept them, so that a later declaration using them needs no fallback.
+var supportedKeywords = map[string]bool{
+	"inherit": true, "none": true, "auto": true, "normal": true,
+	"block": true, "inline": true, "inline-block": true, "hidden": true, "visible": true,
+	"left": true, "right": true, "center": true, "bold": true, "transparent": true,
+	"black": true, "white": true, "gray": true, "silver": true, "red": true, "maroon": true,
+	"green": true, "lime": true, "blue": true, "navy": true, "yellow": true, "olive": true,
+	"purple": true, "fuchsia": true, "aqua": true, "teal": true,
+}
+
+// supportedUnits are the units of CSS 1, which every browser supports.
+var supportedUnits = map[string]bool{
+	"px": true, "em": true, "ex": true, "%": true, "pt": true, "pc": true, "in": true, "cm": true, "mm": true,
+}
+
+// Optimize restructures the stylesheet in place to remove redundancy without changing which
+// declarations apply to any element:
+//   - declarations overridden later in the same block are removed
+//   - adjacent rules with identical selectors are merged
+//   - adjacent rules with identical declarations are merged into a selector list
+//   - adjacent @media blocks with identical queries are merged, and earlier duplicates of an
+//     identical @media block are removed
+//
+// Only adjacent rules are merged, as moving a rule past another can change the cascade.
+func Optimize(s *parser.Stylesheet) {
+	s.Rules = optimizeRules(s.Rules)
+}
+
+func optimizeRules(rules []parser.Node) []parser.Node {
+	for _, rule := range rules {
+		switch r := rule.(type) {
+		case *parser.Selector:
+			// Nested style rules and at-rules are optimized like those of the stylesheet
+			r.Rules = optimizeRules(removeOverridden(r.Rules))
+		case *parser.MediaAtRule:
+			r.Rules = optimizeRules(r.Rules)
+		case *parser.ContainerAtRule:
+			r.Declarations = optimizeRules(r.Declarations)
+		case *parser.SupportsAtRule:
+			r.Rules = optimizeRules(r.Rules)
+		case *parser.LayerAtRule:
+			r.Rules = optimizeRules(r.Rules)
+		}
+	}
+
+	rules = mergeAdjacentSelectors(rules)
+	rules = mergeAdjacentDeclarations(rules)
+	rules = mergeAdjacentMedia(rules)
+	rules = removeDuplicateMedia(rules)
+	return rules
+}
+
+// removeOverridden removes each declaration that a later declaration in the block overrides.
+func removeOverridden(rules []parser.Node) []parser.Node {
+	kept := make([]parser.Node, 0, len(rules))
+	for i, rule := range rules {
+		d, ok := rule.(*parser.Declaration)
+		if ok && overriddenLater(d, rules[i+1:]) {
+			continue
+		}
+		kept = append(kept, rule)
+	}
+	return kept
+}
+
+func overriddenLater(d *parser.Declaration, later []parser.Node) bool {
+	for _, rule := range later {
+		if l, ok := rule.(*parser.Declaration); ok && overrides(d, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// overrides reports whether the later declaration makes the earlier one redundant. Differing
+// values are only dropped when every browser supports the later one, as otherwise the earlier
+// declaration is the fallback for browsers that ignore it, as in height: 100vh; height: 100dvh.
+func overrides(earlier, later *parser.Declaration) bool {
+	if !sameProperty(earlier.Key, later.Key) {
+		return false
+	}
+	if earlier.Important && !later.Important {
+		return false
+	}
+	if declarationKey(earlier) == declarationKey(later) {
+		return true
+	}
+	return supportedEverywhere(later)
+}
+
+func sameProperty(a, b []byte) bool {
+	// Custom property names are case-sensitive
+	if bytes.HasPrefix(a, []byte("--")) {
+		return bytes.Equal(a, b)
+	}
+	return bytes.EqualFold(a, b)
+}
+
+// supportedEverywhere reports whether the declaration's value only uses keywords, numbers and
+// lengths that every browser supports.
+func supportedEverywhere(d *parser.Declaration) bool {
+	if bytes.HasPrefix(d.Key, []byte("--")) || len(d.Value) == 0 {
+		return false
+	}
+	for _, value := range d.Value {
+		v, ok := value.(*parser.BasicValue)
+		if !ok || !(supportedKeywords[string(bytes.ToLower(v.Value))] || supportedLength(v.Value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// supportedLength reports whether the value is a number, or a length or percentage in one of
+// the units of CSS 1.
+func supportedLength(value []byte) bool {
+	end := 0
+	for end < len(value) && (isDigit(value[end]) || value[end] == '.' || (end == 0 && value[end] == '-')) {
+		end++
+	}
+	if end == 0 || !isDigit(value[end-1]) {
+		return false
+	}
+	return end == len(value) || supportedUnits[string(bytes.ToLower(value[end:]))]
+}
+
+// mergeAdjacentSelectors merges each rule into the one before it when their selectors are identical.
+func mergeAdjacentSelectors(rules []parser.Node) []parser.Node {
+	merged := make([]parser.Node, 0, len(rules))
+	for _, rule := range rules {
+		current, ok := rule.(*parser.Selector)
+		if ok && len(merged) > 0 {
+			if previous, ok := merged[len(merged)-1].(*parser.Selector); ok &&
+				onlyDeclarations(previous) && onlyDeclarations(current) &&
+				selectorKey(previous.Selectors) == selectorKey(current.Selectors) {
+				previous.Rules = removeOverridden(append(previous.Rules, current.Rules...))
+				continue
+			}
+		}
+		merged = append(merged, rule)
+	}
+	return merged
+}
+
+// mergeAdjacentDeclarations merges each rule into the one before it when their declarations
+// are identical, by joining their selectors into a list.
+func mergeAdjacentDeclarations(rules []parser.Node) []parser.Node {
+	merged := make([]parser.Node, 0, len(rules))
+	for _, rule := range rules {
+		current, ok := rule.(*parser.Selector)
+		if ok && len(merged) > 0 {
+			if previous, ok := merged[len(merged)-1].(*parser.Selector); ok &&
+				onlyDeclarations(previous) && onlyDeclarations(current) &&
+				mergeableSelector(previous.Selectors) && mergeableSelector(current.Selectors) &&
+				blockKey(previous.Rules) == blockKey(current.Rules) {
+				previous.Selectors = joinSelectorLists(previous.Selectors, current.Selectors)
+				continue
+			}
+		}
+		merged = append(merged, rule)
+	}
+	return merged
+}
+
+// mergeAdjacentMedia merges each @media block into the one before it when their queries are identical.
+func mergeAdjacentMedia(rules []parser.Node) []parser.Node {
+	merged := make([]parser.Node, 0, len(rules))
+	for _, rule := range rules {
+		current, ok := rule.(*parser.MediaAtRule)
+		if ok && len(merged) > 0 {
+			if previous, ok := merged[len(merged)-1].(*parser.MediaAtRule); ok &&
+				mediaQueryKey(previous) == mediaQueryKey(current) {
+				previous.Rules = optimizeRules(append(previous.Rules, current.Rules...))
+				continue
+			}
+		}
+		merged = append(merged, rule)
+	}
+	return merged
+}
+
+// removeDuplicateMedia removes every @media block that is identical to a later one. The later
+// block reapplies all of its rules after anything in between, so the earlier copy has no effect.
+func removeDuplicateMedia(rules []parser.Node) []parser.Node {
+	seen := make(map[string]bool)
+	kept := make([]parser.Node, 0, len(rules))
+	for i := len(rules) - 1; i >= 0; i-- {
+		if media, ok := rules[i].(*parser.MediaAtRule); ok {
+			key := nodeKey(media)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		kept = append(kept, rules[i])
+	}
+
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept
+}
+
+// onlyDeclarations reports whether the rule's block holds nothing but declarations and comments.
+func onlyDeclarations(s *parser.Selector) bool {
+	for _, rule := range s.Rules {
+		switch rule.(type) {
+		case *parser.Declaration, *parser.Comment:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// mergeableSelector reports whether the selector only uses widely supported pseudo-classes and
+// pseudo-elements, so that merging it into a list cannot invalidate the list.
+func mergeableSelector(list parser.SelectorList) bool {
+	for _, complex := range list {
+		for _, compound := range complex.Compounds {
+			for _, simple := range compound.Selectors {
+				pseudo, ok := simple.(*parser.PseudoSelector)
+				if !ok {
+					continue
+				}
+				if !safePseudos[strings.ToLower(string(pseudo.Name))] || !mergeableSelector(pseudo.Selectors) {
+					return false
+				}
+				if pseudo.Nth != nil && pseudo.Nth.Of != nil {
+					// The selector list argument of :nth-child() is not widely supported
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// joinSelectorLists joins two selector lists, dropping selectors already in the first list.
+func joinSelectorLists(a, b parser.SelectorList) parser.SelectorList {
+	seen := make(map[string]bool)
+	for _, complex := range a {
+		seen[selectorKey(parser.SelectorList{complex})] = true
+	}
+
+	joined := append(parser.SelectorList{}, a...)
+	for _, complex := range b {
+		key := selectorKey(parser.SelectorList{complex})
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		joined = append(joined, complex)
+	}
+	return joined
+}
+
+// selectorKey returns a string that is equal for identical selectors.
+func selectorKey(list parser.SelectorList) string {
+	return nodeKey(&parser.Selector{Selectors: list})
+}
+
+// blockKey returns a string that is equal for blocks with identical declarations and comments,
+// so that merging two blocks never drops a comment.
+func blockKey(rules []parser.Node) string {
+	var sb strings.Builder
+	for _, rule := range rules {
+		sb.WriteString(nodeKey(rule))
+	}
+	return sb.String()
+}
+
+func declarationKey(d *parser.Declaration) string {
+	return nodeKey(d)
+}
+
+func mediaQueryKey(m *parser.MediaAtRule) string {
+	return nodeKey(&parser.MediaAtRule{Name: m.Name, Query: m.Query})
+}
+
+// nodeKey returns the compact CSS of a node, which is equal for identical nodes.
+func nodeKey(node parser.Node) string {
+	key, err := printer.Print(node, printer.WithCompact())
+	if err != nil {
+		// Nodes the printer does not support are never considered equal
+		return err.Error() + "\x00" + node.String()
+	}
+	return key
+}
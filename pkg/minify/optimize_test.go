@@ -0,0 +1,101 @@
+package minify_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/minify"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Removes overridden declarations",
+			input:    ".a { color: red; margin: 0; color: blue; }",
+			expected: ".a{margin:0;color:blue}",
+		},
+		{
+			name:     "Keeps important declarations",
+			input:    ".a { color: red !important; color: blue; }",
+			expected: ".a{color:red!important;color:blue}",
+		},
+		{
+			name:     "Keeps fallbacks",
+			input:    ".a { display: -webkit-box; display: flex; width: 100px; width: calc(100% - 10px); }",
+			expected: ".a{display:-webkit-box;display:flex;width:100px;width:calc(100% - 10px)}",
+		},
+		{
+			name:     "Keeps fallbacks for newer units",
+			input:    ".a { height: 100vh; height: 100dvh; }",
+			expected: ".a{height:100vh;height:100dvh}",
+		},
+		{
+			name:     "Keeps fallbacks for newer keywords",
+			input:    ".a { width: auto; width: fit-content; display: block; display: contents; }",
+			expected: ".a{width:auto;width:fit-content;display:block;display:contents}",
+		},
+		{
+			name:     "Removes declarations overridden by widely supported values",
+			input:    ".a { width: fit-content; width: 50%; display: contents; display: none; margin: 1rem; margin: 0 auto; }",
+			expected: ".a{width:50%;display:none;margin:0 auto}",
+		},
+		{
+			name:     "Merges adjacent rules with identical selectors",
+			input:    ".a { color: red; } .a { margin: 0; color: blue; }",
+			expected: ".a{margin:0;color:blue}",
+		},
+		{
+			name:     "Merges adjacent rules with identical declarations",
+			input:    ".a { color: red; } .b, .a { color: red; } .c:hover { color: red; }",
+			expected: ".a,.b,.c:hover{color:red}",
+		},
+		{
+			name:     "Does not merge rules that are not adjacent",
+			input:    ".a { color: red; } .b { color: blue; } .a { margin: 0; } .c { color: red; }",
+			expected: ".a{color:red}.b{color:blue}.a{margin:0}.c{color:red}",
+		},
+		{
+			name:     "Does not merge selectors that could invalidate a list",
+			input:    ".a::-moz-selection { color: red; } .a::selection { color: red; }",
+			expected: ".a::-moz-selection{color:red}.a::selection{color:red}",
+		},
+		{
+			name:     "Does not merge selectors with unsupported pseudo-classes in arguments",
+			input:    ".a:not(:focus-visible) { color: red; } .b:nth-child(odd of .c) { color: red; } .d:not(:hover) { color: red; } .e { color: red; }",
+			expected: ".a:not(:focus-visible){color:red}.b:nth-child(2n+1 of .c){color:red}.d:not(:hover),.e{color:red}",
+		},
+		{
+			name:     "Merges adjacent media blocks",
+			input:    "@media print { .a { color: red; } } @media print { .b { color: red; } }",
+			expected: "@media print{.a,.b{color:red}}",
+		},
+		{
+			name:     "Removes earlier duplicate media blocks",
+			input:    "@media print { .a { color: red; } } .a { color: blue; } @media print { .a { color: red; } }",
+			expected: ".a{color:blue}@media print{.a{color:red}}",
+		},
+		{
+			name:     "Optimizes nested rules",
+			input:    ".a { color: red; & .b { margin: 0; } > .c { margin: 0; } .d { color: red; } .d { color: blue; } color: blue; }",
+			expected: ".a{& .b,>.c{margin:0}.d{color:blue}color:blue}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := minify.Minify(&out, strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, out.String()); diff != "" {
+				t.Errorf("Output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+// AnPlusB is the argument of the nth- pseudo-classes, which match the elements at positions
+// A*n+B for any n >= 0, counting from 1. "odd" is 2n+1 and "even" is 2n.
+type AnPlusB struct {
+	A int
+	B int
+	// Of restricts the elements counted by :nth-child() and :nth-last-child() to those
+	// matching a selector list, as in :nth-child(2n of .item)
+	Of SelectorList
+}
+
+func (n *AnPlusB) String() string {
+	if n.Of == nil {
+		return fmt.Sprintf("AnPlusB{A: %d, B: %d}", n.A, n.B)
+	}
+	return fmt.Sprintf("AnPlusB{A: %d, B: %d, Of: %s}", n.A, n.B, n.Of.inline())
+}
+
+// parseAnPlusB parses the argument of an nth- pseudo-class up to its closing parenthesis. The
+// selector list after "of" is only accepted when allowOf is set.
+func (pv *ParseVisitor) parseAnPlusB(allowOf bool) *AnPlusB {
+	start := pv.currentToken
+	// The lexer splits An+B unevenly, such as "2n-1" into "2" and "n-1", so the tokens are
+	// joined back into text, keeping whether they were separated by whitespace
+	var text strings.Builder
+	for !pv.currentTokenIs(tokens.RPAREN) && !pv.currentTokenIs(tokens.EOF) {
+		if pv.currentTokenIs(tokens.IDENT) && strings.EqualFold(string(pv.currentToken.Literal), "of") && text.Len() > 0 {
+			break
+		}
+		if pv.currentToken.SpaceBefore && text.Len() > 0 {
+			text.WriteByte(' ')
+		}
+		text.Write(pv.currentToken.Literal)
+		pv.advance()
+	}
+
+	nth, ok := parseAnPlusBText(text.String())
+	if !ok {
+		pv.addError(fmt.Sprintf("Invalid An+B expression: %q", text.String()), start)
+		return nil
+	}
+
+	if pv.currentTokenIs(tokens.IDENT) {
+		if !allowOf {
+			pv.addError("A selector list is only allowed in :nth-child() and :nth-last-child()", pv.currentToken)
+		}
+		pv.advance() // Consume 'of'
+		nth.Of = pv.parseSelectorList(nil, false)
+	}
+	return nth
+}
+
+// parseAnPlusBText parses An+B syntax, such as "odd", "-n+3" or "2n - 1".
+func parseAnPlusBText(text string) (*AnPlusB, bool) {
+	text = strings.ToLower(text)
+	switch text {
+	case "odd":
+		return &AnPlusB{A: 2, B: 1}, true
+	case "even":
+		return &AnPlusB{A: 2, B: 0}, true
+	}
+
+	i := strings.IndexByte(text, 'n')
+	if i < 0 {
+		b, err := strconv.Atoi(text)
+		return &AnPlusB{B: b}, err == nil
+	}
+
+	nth := &AnPlusB{}
+	switch coefficient := text[:i]; coefficient {
+	case "", "+":
+		nth.A = 1
+	case "-":
+		nth.A = -1
+	default:
+		a, err := strconv.Atoi(coefficient)
+		if err != nil {
+			return nil, false
+		}
+		nth.A = a
+	}
+
+	// The sign of B can be separated from both n and the digits by whitespace
+	rest := strings.TrimSpace(text[i+1:])
+	if rest == "" {
+		return nth, true
+	}
+	if rest[0] != '+' && rest[0] != '-' {
+		return nil, false
+	}
+	digits := strings.TrimSpace(rest[1:])
+	if digits == "" || digits[0] < '0' || digits[0] > '9' {
+		return nil, false
+	}
+	b, err := strconv.Atoi(digits)
+	if err != nil {
+		return nil, false
+	}
+	if rest[0] == '-' {
+		b = -b
+	}
+	nth.B = b
+	return nth, true
+}
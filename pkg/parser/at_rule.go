@@ -6,14 +6,13 @@ import (
 )
 
 const (
-    NodeAtRule NodeType = "AT_RULE"
+	NodeAtRule NodeType = "AT_RULE"
 )
 
 func init() {
 	RegisterNodeType(NodeAtRule, visitAt)
 }
 
-
 type AtRule interface {
 	Node
 	AtType() AtType
@@ -23,6 +22,7 @@ type AtHandler func(*ParseVisitor, AtRule)
 type AtInit func() AtRule
 
 func (pv *ParseVisitor) getAtRule() Node {
+	pos := pv.pos()
 	pv.advance() // Consume the @
 	if !pv.currentTokenIs(tokens.IDENT) {
 		pv.addError("Expected identifier after @", pv.currentToken)
@@ -36,7 +36,11 @@ func (pv *ParseVisitor) getAtRule() Node {
 		return nil
 	}
 
-	return initFn()
+	node := initFn()
+	if p, ok := node.(interface{ setPos(Position) }); ok {
+		p.setPos(pos)
+	}
+	return node
 }
 
 func visitAt(pv *ParseVisitor, node Node) {
@@ -48,4 +52,48 @@ func visitAt(pv *ParseVisitor, node Node) {
 
 	handler := GetAtHandler(atRule)
 	handler(pv, atRule)
+	if p, ok := node.(interface{ setEnd(int) }); ok {
+		p.setEnd(pv.lastEnd)
+	}
+}
+
+// parseRuleBlock parses the rules of an at-rule block whose opening '{' has been consumed, up to
+// and including the closing '}'. The block can hold comments, style rules and nested at-rules.
+func (pv *ParseVisitor) parseRuleBlock(name string) []Node {
+	if pv.nesting > 0 {
+		// Within a style rule, the block holds declarations like the style rule's own block
+		return pv.parseStyleBlock(fmt.Sprintf("Expected '}' to close %s block", name))
+	}
+
+	var rules []Node
+	for !pv.currentTokenIs(tokens.RBRACE) && !pv.currentTokenIs(tokens.EOF) {
+		switch pv.currentToken.Type {
+		case tokens.COMMENT:
+			comment := &Comment{Text: pv.currentToken.Literal}
+			visitComment(pv, comment)
+			rules = append(rules, comment)
+		case tokens.DOT, tokens.HASH, tokens.COLOR, tokens.COLON, tokens.DBLCOLON, tokens.IDENT, tokens.LBRACKET,
+			tokens.ASTERISK, tokens.PIPE, tokens.AMPERSAND:
+			selector := &Selector{
+				Selectors: SelectorList{},
+				Rules:     make([]Node, 0),
+			}
+			visitSelector(pv, selector)
+			rules = append(rules, selector)
+		case tokens.AT:
+			atRule := pv.getAtRule()
+			if atRule == nil {
+				pv.skipToNextRule()
+				continue
+			}
+			visitAt(pv, atRule)
+			rules = append(rules, atRule)
+		default:
+			pv.addError(fmt.Sprintf("Unexpected token in %s block", name), pv.currentToken)
+			pv.advance()
+		}
+	}
+
+	pv.consume(tokens.RBRACE, fmt.Sprintf("Expected '}' to close %s block", name))
+	return rules
 }
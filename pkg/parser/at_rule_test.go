@@ -71,12 +71,14 @@ func TestFontFaceAtRule(t *testing.T) {
 										&StringValue{Value: []byte("Bitstream Vera Serif Bold")},
 									},
 								},
+								&BasicValue{Value: []byte(",")},
 								&FunctionValue{
 									Name: []byte("local"),
 									Arguments: []Value{
 										&StringValue{Value: []byte("BitstreamVeraSerif-Bold")},
 									},
 								},
+								&BasicValue{Value: []byte(",")},
 								&FunctionValue{
 									Name: []byte("url"),
 									Arguments: []Value{
@@ -170,10 +172,11 @@ func TestCharsetAtRule(t *testing.T) {
 						Charset: &StringValue{Value: []byte("UTF-8")},
 					},
 					&Selector{
-						Selectors: []SelectorValue{{Type: Element, Value: []byte("body")}},
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("body")}}}}}},
 						Rules: []Node{
 							&Declaration{Key: []byte("font-family"), Value: []Value{
 								&BasicValue{Value: []byte("Arial")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("sans-serif")},
 							}},
 						},
@@ -6,8 +6,8 @@ import (
 	"github.com/aledsdavies/pristinecss/pkg/tokens"
 )
 
-const(
-    Charset AtType = "charset"
+const (
+	Charset AtType = "charset"
 )
 
 func init() {
@@ -15,17 +15,19 @@ func init() {
 }
 
 type CharsetAtRule struct {
+	Position
+
 	Charset Value
 }
 
-func (r *CharsetAtRule) Type() NodeType   { return NodeAtRule }
-func (r *CharsetAtRule) AtType() AtType   { return Charset }
+func (r *CharsetAtRule) Type() NodeType { return NodeAtRule }
+func (r *CharsetAtRule) AtType() AtType { return Charset }
 func (r *CharsetAtRule) String() string {
 	return fmt.Sprintf("CharsetAtRule{Charset: %q}", r.Charset)
 }
 
 func visitCharsetAtRule(pv *ParseVisitor, node AtRule) {
-    r := node.(*CharsetAtRule)
+	r := node.(*CharsetAtRule)
 	pv.advance() // Consume 'charset'
 
 	if pv.currentTokenIs(tokens.STRING) {
@@ -37,4 +39,3 @@ func visitCharsetAtRule(pv *ParseVisitor, node AtRule) {
 
 	pv.consume(tokens.SEMICOLON, "Expected ';' after @charset rule")
 }
-
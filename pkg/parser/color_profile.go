@@ -16,13 +16,15 @@ func init() {
 }
 
 type ColorProfileAtRule struct {
+	Position
+
 	Name         []byte
 	IsDeviceCMYK bool
 	Declarations []Declaration
 }
 
-func (r *ColorProfileAtRule) Type() NodeType   { return NodeAtRule }
-func (r *ColorProfileAtRule) AtType() AtType   { return ColorProfile }
+func (r *ColorProfileAtRule) Type() NodeType { return NodeAtRule }
+func (r *ColorProfileAtRule) AtType() AtType { return ColorProfile }
 func (r *ColorProfileAtRule) String() string {
 	var sb strings.Builder
 	sb.WriteString("ColorProfileAtRule{\n")
@@ -12,19 +12,22 @@ func init() {
 
 var _ Node = (*Comment)(nil)
 
-
 type Comment struct {
-    Text []byte
+	Position
+	Text []byte
 }
 
-func (c *Comment) Type() NodeType { return NodeComment }
+func (c *Comment) Type() NodeType       { return NodeComment }
 func (c *Comment) ValueType() ValueType { return ValueComment }
 func (c *Comment) String() string {
-    return fmt.Sprintf("Comment{Text: %q}", string(c.Text))
+	return fmt.Sprintf("Comment{Text: %q}", string(c.Text))
 }
 
 func visitComment(pv *ParseVisitor, node Node) {
-    // The comment's text has already been set when the node was created,
-    // so we just need to advance past the comment token.
-    pv.advance()
+	// The comment's text has already been set when the node was created,
+	// so we just need to record its position and advance past the comment token.
+	c := node.(*Comment)
+	c.Position = pv.pos()
+	pv.advance()
+	pv.endPos(&c.Position)
 }
@@ -17,9 +17,7 @@ func TestCommentsInSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Attribute, Value: []byte(`[type="search"]`)},
-						},
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&AttributeSelector{Name: []byte("type"), Matcher: MatchEquals, Value: []byte("search")}}}}}},
 						Rules: []Node{
 							&Comment{Text: []byte("/* 1 */")},
 							&Declaration{
@@ -48,9 +46,7 @@ func TestCommentsInSelectors(t *testing.T) {
                 * 2. Correct the outline style in Safari.
                 */`)},
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Attribute, Value: []byte(`[type="search"]`)},
-						},
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&AttributeSelector{Name: []byte("type"), Matcher: MatchEquals, Value: []byte("search")}}}}}},
 						Rules: []Node{
 							&Declaration{
 								Key:   []byte("outline-offset"),
@@ -74,9 +70,7 @@ func TestCommentsInSelectors(t *testing.T) {
 				Rules: []Node{
 					&Comment{Text: []byte("/* Header styles */")},
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Class, Value: []byte(".header")},
-						},
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("header")}}}}}},
 						Rules: []Node{
 							&Declaration{
 								Key:   []byte("color"),
@@ -16,26 +16,34 @@ func init() {
 }
 
 type ContainerAtRule struct {
-	Name        []byte
-	Query       ContainerQuery
+	Position
+
+	Name         []byte
+	Query        ContainerQuery
 	Declarations []Node
 }
 
 type ContainerQuery struct {
+	Position
+
 	Conditions []ContainerCondition
 }
 
 type ContainerCondition struct {
+	Position
+
 	Features []ContainerFeature
 }
 
 type ContainerFeature struct {
+	Position
+
 	Name  []byte
 	Value []byte
 }
 
-func (r *ContainerAtRule) Type() NodeType   { return NodeAtRule }
-func (r *ContainerAtRule) AtType() AtType   { return Container }
+func (r *ContainerAtRule) Type() NodeType { return NodeAtRule }
+func (r *ContainerAtRule) AtType() AtType { return Container }
 func (r *ContainerAtRule) String() string {
 	var sb strings.Builder
 	sb.WriteString("ContainerAtRule{\n")
@@ -102,6 +110,11 @@ func visitContainerAtRule(pv *ParseVisitor, node AtRule) {
 		return
 	}
 
+	if pv.nesting > 0 {
+		c.Declarations = pv.parseStyleBlock("Expected '}' to close @container rule")
+		return
+	}
+
 	// Parse declarations
 	for !pv.currentTokenIs(tokens.RBRACE) && !pv.currentTokenIs(tokens.EOF) {
 		switch pv.currentToken.Type {
@@ -111,9 +124,10 @@ func visitContainerAtRule(pv *ParseVisitor, node AtRule) {
 			}
 			visitDeclaration(pv, declaration)
 			c.Declarations = append(c.Declarations, declaration)
-		case tokens.DOT, tokens.HASH, tokens.COLON, tokens.DBLCOLON:
+		case tokens.DOT, tokens.HASH, tokens.COLOR, tokens.COLON, tokens.DBLCOLON, tokens.ASTERISK, tokens.PIPE,
+			tokens.AMPERSAND:
 			selector := &Selector{
-				Selectors: make([]SelectorValue, 0),
+				Selectors: SelectorList{},
 				Rules:     make([]Node, 0),
 			}
 			visitSelector(pv, selector)
@@ -133,8 +147,10 @@ func visitContainerAtRule(pv *ParseVisitor, node AtRule) {
 
 func parseContainerQuery(pv *ParseVisitor) *ContainerQuery {
 	query := &ContainerQuery{
+		Position:   pv.pos(),
 		Conditions: make([]ContainerCondition, 0),
 	}
+	defer pv.endPos(&query.Position)
 
 	for !pv.currentTokenIs(tokens.LBRACE) && !pv.currentTokenIs(tokens.EOF) {
 		condition := parseContainerCondition(pv)
@@ -154,6 +170,7 @@ func parseContainerQuery(pv *ParseVisitor) *ContainerQuery {
 
 func parseContainerCondition(pv *ParseVisitor) *ContainerCondition {
 	condition := &ContainerCondition{
+		Position: pv.pos(),
 		Features: make([]ContainerFeature, 0),
 	}
 
@@ -175,12 +192,13 @@ func parseContainerCondition(pv *ParseVisitor) *ContainerCondition {
 	if !pv.consume(tokens.RPAREN, "Expected ')' to close container condition") {
 		return nil
 	}
+	pv.endPos(&condition.Position)
 
 	return condition
 }
 
 func parseContainerFeature(pv *ParseVisitor) *ContainerFeature {
-	feature := &ContainerFeature{}
+	feature := &ContainerFeature{Position: pv.pos()}
 
 	if !pv.currentTokenIs(tokens.IDENT) {
 		pv.addError("Expected identifier for container feature", pv.currentToken)
@@ -200,13 +218,14 @@ func parseContainerFeature(pv *ParseVisitor) *ContainerFeature {
 
 		// Handle units like 'px'
 		if pv.currentTokenIs(tokens.IDENT) {
-			feature.Value = append(feature.Value, pv.currentToken.Literal...)
+			feature.Value = concat(feature.Value, pv.currentToken.Literal)
 			pv.advance()
 		}
 	} else {
 		pv.addError("Expected value for container feature", pv.currentToken)
 		return nil
 	}
+	pv.endPos(&feature.Position)
 
 	return feature
 }
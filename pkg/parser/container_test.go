@@ -29,7 +29,7 @@ func TestContainerAtRule(t *testing.T) {
 						},
 						Declarations: []Node{
 							&Selector{
-								Selectors: []SelectorValue{{Type: Class, Value: []byte(".card")}},
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("card")}}}}}},
 								Rules: []Node{
 									&Declaration{Key: []byte("font-size"), Value: []Value{&BasicValue{Value: []byte("1.5em")}}},
 								},
@@ -61,7 +61,7 @@ func TestContainerAtRule(t *testing.T) {
 						},
 						Declarations: []Node{
 							&Selector{
-								Selectors: []SelectorValue{{Type: Class, Value: []byte(".sidebar")}},
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("sidebar")}}}}}},
 								Rules: []Node{
 									&Declaration{Key: []byte("flex"), Value: []Value{
 										&BasicValue{Value: []byte("1")},
@@ -102,7 +102,7 @@ func TestContainerAtRule(t *testing.T) {
 						},
 						Declarations: []Node{
 							&Selector{
-								Selectors: []SelectorValue{{Type: Class, Value: []byte(".container")}},
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("container")}}}}}},
 								Rules: []Node{
 									&Declaration{Key: []byte("display"), Value: []Value{&BasicValue{Value: []byte("flex")}}},
 									&Declaration{Key: []byte("flex-wrap"), Value: []Value{&BasicValue{Value: []byte("wrap")}}},
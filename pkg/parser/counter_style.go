@@ -16,12 +16,14 @@ func init() {
 }
 
 type CounterStyleAtRule struct {
+	Position
+
 	Name         []byte
 	Declarations []Declaration
 }
 
-func (r *CounterStyleAtRule) Type() NodeType   { return NodeAtRule }
-func (r *CounterStyleAtRule) AtType() AtType   { return CounterStyle }
+func (r *CounterStyleAtRule) Type() NodeType { return NodeAtRule }
+func (r *CounterStyleAtRule) AtType() AtType { return CounterStyle }
 func (r *CounterStyleAtRule) String() string {
 	var sb strings.Builder
 	sb.WriteString("CounterStyleAtRule{\n")
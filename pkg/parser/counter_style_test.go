@@ -75,28 +75,40 @@ func TestCounterStyleAtRule(t *testing.T) {
 							{Key: []byte("additive-symbols"), Value: []Value{
 								&BasicValue{Value: []byte("1000")},
 								&BasicValue{Value: []byte("M")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("900")},
 								&BasicValue{Value: []byte("CM")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("500")},
 								&BasicValue{Value: []byte("D")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("400")},
 								&BasicValue{Value: []byte("CD")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("100")},
 								&BasicValue{Value: []byte("C")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("90")},
 								&BasicValue{Value: []byte("XC")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("50")},
 								&BasicValue{Value: []byte("L")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("40")},
 								&BasicValue{Value: []byte("XL")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("10")},
 								&BasicValue{Value: []byte("X")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("9")},
 								&BasicValue{Value: []byte("IX")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("5")},
 								&BasicValue{Value: []byte("V")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("4")},
 								&BasicValue{Value: []byte("IV")},
+								&BasicValue{Value: []byte(",")},
 								&BasicValue{Value: []byte("1")},
 								&BasicValue{Value: []byte("I")},
 							}},
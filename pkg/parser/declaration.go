@@ -17,6 +17,8 @@ func init() {
 var _ Node = (*Declaration)(nil)
 
 type Declaration struct {
+	Position
+
 	Key       []byte
 	Value     []Value
 	Important bool
@@ -40,6 +42,8 @@ func (d *Declaration) String() string {
 
 func visitDeclaration(pv *ParseVisitor, node Node) {
 	d := node.(*Declaration)
+	d.Position = pv.pos()
+	defer pv.endPos(&d.Position)
 	pv.advance() // Consume property name
 	if !pv.consume(tokens.COLON, "Expected ':' after property name") {
 		pv.skipToNextSemicolonOrBrace()
@@ -63,9 +67,9 @@ func visitDeclaration(pv *ParseVisitor, node Node) {
 				pv.skipToNextSemicolonOrBrace()
 				return
 			}
-		case tokens.COMMA:
-			// Skip the comma and continue parsing values
-			pv.advance()
+		case tokens.COMMA, tokens.DIVIDE:
+			// Separators are kept so the value can be printed back
+			d.Value = append(d.Value, pv.parseValue())
 		default:
 			pv.addError("Unexpected token in declaration value", pv.currentToken)
 			pv.skipToNextSemicolonOrBrace()
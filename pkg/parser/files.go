@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+)
+
+// ParsedFile is the result of parsing one of the files matched by ParseFiles.
+type ParsedFile struct {
+	// Path is the path of the file within the filesystem
+	Path       string
+	Stylesheet *Stylesheet
+	Errors     []ParseError
+}
+
+// ParseFiles parses every file in fsys that matches one of the patterns, spreading the work over
+// GOMAXPROCS goroutines.
+//
+// Parameters:
+// - fsys: The filesystem the files are read from.
+// - patterns: fs.Glob patterns, such as "styles/*.css". A file matched by several patterns is
+// parsed once.
+//
+// Returns:
+// - The parsed files sorted by path, whatever order they finished parsing in. Each node records
+// the file's path in its Position.
+// - An error if a pattern is malformed or a file could not be read, in which case no files are
+// returned.
+func ParseFiles(fsys fs.FS, patterns ...string) ([]ParsedFile, error) {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				paths = append(paths, match)
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	results := make([]ParsedFile, len(paths))
+	readErrors := make([]error, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.GOMAXPROCS(0), len(paths)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], readErrors[i] = parseFile(fsys, paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range readErrors {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func parseFile(fsys fs.FS, path string) (ParsedFile, error) {
+	content, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return ParsedFile{}, err
+	}
+	stylesheet, errors := Parse(lexer.LexBytes(content), WithSource(path))
+	return ParsedFile{Path: path, Stylesheet: stylesheet, Errors: errors}, nil
+}
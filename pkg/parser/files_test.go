@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+)
+
+func TestParseFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"styles/b.css":      {Data: []byte(".b { color: blue; }")},
+		"styles/a.css":      {Data: []byte(".a { color: red; }")},
+		"styles/broken.css": {Data: []byte(".c { color: red; } }")},
+		"styles/notes.txt":  {Data: []byte("not css")},
+		"theme.css":         {Data: []byte("@media print { .d { color: black; } }")},
+	}
+
+	files, err := ParseFiles(fsys, "styles/*.css", "*.css", "styles/a.css")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"styles/a.css", "styles/b.css", "styles/broken.css", "theme.css"}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected %d files, got %d", len(expected), len(files))
+	}
+	for i, file := range files {
+		if file.Path != expected[i] {
+			t.Errorf("File %d: expected %s, got %s", i, expected[i], file.Path)
+		}
+		if len(file.Stylesheet.Rules) == 0 {
+			t.Errorf("%s: expected rules to be parsed", file.Path)
+			continue
+		}
+		if pos := file.Stylesheet.Rules[0].(Positioned).Pos(); pos.Source != file.Path {
+			t.Errorf("%s: expected the source %s, got %s", file.Path, file.Path, pos.Source)
+		}
+		if hasErrors := len(file.Errors) > 0; hasErrors != (file.Path == "styles/broken.css") {
+			t.Errorf("%s: unexpected errors %v", file.Path, file.Errors)
+		}
+	}
+}
+
+func TestParseFilesErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.css":            {Data: []byte(".a { color: red; }")},
+		"folder.css/b.css": {Data: []byte(".b { color: red; }")},
+	}
+
+	if _, err := ParseFiles(fsys, "[a"); err == nil {
+		t.Errorf("Expected an error for a malformed pattern")
+	}
+	if _, err := ParseFiles(fsys, "*.css"); err == nil {
+		t.Errorf("Expected an error for a file that cannot be read")
+	}
+}
+
+func TestParseConcurrently(t *testing.T) {
+	inputs := make([]string, 50)
+	expected := make([]string, len(inputs))
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf(".c%d { width: %dpx; } @media (min-width: %dpx) { #id%d { color: red; } }", i, i, i, i)
+		stylesheet, _ := Parse(lexer.Lex(strings.NewReader(inputs[i])))
+		expected[i] = stylesheet.String()
+	}
+
+	var wg sync.WaitGroup
+	for i := range inputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stylesheet, errors := Parse(lexer.Lex(strings.NewReader(inputs[i])))
+			if len(errors) > 0 {
+				t.Errorf("Input %d: unexpected errors %v", i, errors)
+			}
+			if got := stylesheet.String(); got != expected[i] {
+				t.Errorf("Input %d: expected\n%s\ngot\n%s", i, expected[i], got)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -6,8 +6,8 @@ import (
 	"github.com/aledsdavies/pristinecss/pkg/tokens"
 )
 
-const(
-    FontFace AtType = "font-face"
+const (
+	FontFace AtType = "font-face"
 )
 
 func init() {
@@ -15,11 +15,13 @@ func init() {
 }
 
 type FontFaceAtRule struct {
+	Position
+
 	Declarations []Declaration
 }
 
-func (r *FontFaceAtRule) Type() NodeType   { return NodeAtRule }
-func (r *FontFaceAtRule) AtType() AtType   { return FontFace }
+func (r *FontFaceAtRule) Type() NodeType { return NodeAtRule }
+func (r *FontFaceAtRule) AtType() AtType { return FontFace }
 func (r *FontFaceAtRule) String() string {
 	var sb strings.Builder
 	sb.WriteString("FontFaceAtRule{\n")
@@ -34,7 +36,7 @@ func (r *FontFaceAtRule) String() string {
 }
 
 func visitFontFaceAtRule(pv *ParseVisitor, node AtRule) {
-    ff := node.(*FontFaceAtRule)
+	ff := node.(*FontFaceAtRule)
 	pv.advance() // Consume 'font-face'
 
 	if !pv.consume(tokens.LBRACE, "Expected '{' after @font-face") {
@@ -45,7 +47,7 @@ func visitFontFaceAtRule(pv *ParseVisitor, node AtRule) {
 		declaration := &Declaration{
 			Key: pv.currentToken.Literal,
 		}
-        visitDeclaration(pv, declaration)
+		visitDeclaration(pv, declaration)
 		ff.Declarations = append(ff.Declarations, *declaration)
 
 		if pv.currentTokenIs(tokens.SEMICOLON) {
@@ -16,17 +16,21 @@ func init() {
 }
 
 type FontFeatureValuesAtRule struct {
+	Position
+
 	FontFamilies [][]byte
 	Blocks       []FontFeatureValuesBlock
 }
 
 type FontFeatureValuesBlock struct {
+	Position
+
 	Name         []byte
 	Declarations []Declaration
 }
 
-func (r *FontFeatureValuesAtRule) Type() NodeType   { return NodeAtRule }
-func (r *FontFeatureValuesAtRule) AtType() AtType   { return FontFeatureValues }
+func (r *FontFeatureValuesAtRule) Type() NodeType { return NodeAtRule }
+func (r *FontFeatureValuesAtRule) AtType() AtType { return FontFeatureValues }
 func (r *FontFeatureValuesAtRule) String() string {
 	var sb strings.Builder
 	sb.WriteString("FontFeatureValuesAtRule{\n")
@@ -100,6 +104,7 @@ func visitFontFeatureValuesAtRule(pv *ParseVisitor, node AtRule) {
 			pv.skipToNextSemicolonOrBrace()
 			continue
 		}
+		pos := pv.pos()
 		pv.advance() // Consume '@'
 
 		if !pv.currentTokenIs(tokens.IDENT) {
@@ -109,7 +114,8 @@ func visitFontFeatureValuesAtRule(pv *ParseVisitor, node AtRule) {
 		}
 
 		block := FontFeatureValuesBlock{
-			Name: pv.currentToken.Literal,
+			Position: pos,
+			Name:     pv.currentToken.Literal,
 		}
 		pv.advance()
 
@@ -135,6 +141,7 @@ func visitFontFeatureValuesAtRule(pv *ParseVisitor, node AtRule) {
 		}
 
 		pv.consume(tokens.RBRACE, "Expected '}' to close feature value block")
+		pv.endPos(&block.Position)
 		ffv.Blocks = append(ffv.Blocks, block)
 	}
 
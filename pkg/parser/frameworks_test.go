@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
@@ -40,3 +41,24 @@ func TestCanParseFrameworksWithoutError(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStreamMatchesParse(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "..", "test-data", "frameworks", "bulma.css"))
+	if err != nil {
+		t.Fatalf("Could not read the file: %v", err)
+	}
+
+	expected, expectedErrors := Parse(lexer.Lex(bytes.NewReader(content)))
+	scanner := lexer.NewScanner(bytes.NewReader(content), lexer.WithChunkSize(512))
+	stylesheet, errors := ParseStream(scanner)
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(errors) != len(expectedErrors) {
+		t.Errorf("Expected %d errors, got %d", len(expectedErrors), len(errors))
+	}
+	if stylesheet.String() != expected.String() {
+		t.Errorf("The streamed stylesheet does not match the parsed stylesheet")
+	}
+}
@@ -9,8 +9,8 @@ import (
 	"github.com/aledsdavies/pristinecss/pkg/tokens"
 )
 
-const(
-    Import AtType = "import"
+const (
+	Import AtType = "import"
 )
 
 func init() {
@@ -18,6 +18,8 @@ func init() {
 }
 
 type ImportAtRule struct {
+	Position
+
 	URL      Value
 	Layer    Value
 	Media    MediaQuery
@@ -25,10 +27,13 @@ type ImportAtRule struct {
 }
 
 type SupportsCondition interface {
+	Positioned
 	supportCondition()
 }
 
 type SupportsDecleration struct {
+	Position
+
 	Key   []byte
 	Value []Value
 }
@@ -36,6 +41,8 @@ type SupportsDecleration struct {
 func (SupportsDecleration) supportCondition() {}
 
 type SupportsFunction struct {
+	Position
+
 	Name []byte
 	Args []byte
 }
@@ -43,25 +50,31 @@ type SupportsFunction struct {
 func (SupportsFunction) supportCondition() {}
 
 type SupportsOperator struct {
+	Position
+
 	Operator string
 }
 
 func (SupportsOperator) supportCondition() {}
 
 type SupportsNot struct {
+	Position
+
 	Condition SupportsCondition
 }
 
 func (SupportsNot) supportCondition() {}
 
 type SupportsGroup struct {
+	Position
+
 	Conditions []SupportsCondition
 }
 
 func (SupportsGroup) supportCondition() {}
 
-func (r *ImportAtRule) Type() NodeType   { return NodeAtRule }
-func (r *ImportAtRule) AtType() AtType   { return Import }
+func (r *ImportAtRule) Type() NodeType { return NodeAtRule }
+func (r *ImportAtRule) AtType() AtType { return Import }
 func (r *ImportAtRule) String() string {
 	var sb strings.Builder
 	sb.WriteString("ImportAtRule{\n")
@@ -121,7 +134,7 @@ func supportConditionToString(condition SupportsCondition, indentLevel int) stri
 }
 
 func visitImportAtRule(pv *ParseVisitor, node AtRule) {
-    i := node.(*ImportAtRule)
+	i := node.(*ImportAtRule)
 	pv.advance() // Consume 'import'
 	if pv.currentTokenIs(tokens.URI) || pv.currentTokenIs(tokens.STRING) {
 		i.URL = pv.parseValue()
@@ -134,7 +147,7 @@ func visitImportAtRule(pv *ParseVisitor, node AtRule) {
 		currTok := string(pv.currentToken.Literal)
 		switch {
 		case pv.currentTokenIs(tokens.IDENT) && currTok == "supports":
-            pv.advance() // consume supports
+			pv.advance() // consume supports
 			i.Supports = pv.parseSupportsCondition()
 		case pv.currentTokenIs(tokens.IDENT) && currTok == "layer":
 			i.Layer = pv.parseValue()
@@ -151,16 +164,20 @@ func visitImportAtRule(pv *ParseVisitor, node AtRule) {
 
 func (pv *ParseVisitor) parseSupportsCondition() SupportsCondition {
 	if pv.currentTokenIs(tokens.LPAREN) {
+		// A negation or group spans its parentheses, and a function or declaration its contents
+		pos := pv.pos()
 		pv.advance() // Consume '('
 
 		if string(pv.currentToken.Literal) == "not" {
 			pv.advance() // Consume 'not'
 			notCondition := pv.parseSupportsCondition()
 			pv.consume(tokens.RPAREN, "Expected ')' to close not condition")
-			return &SupportsNot{Condition: notCondition}
+			not := &SupportsNot{Position: pos, Condition: notCondition}
+			pv.endPos(&not.Position)
+			return not
 		} else if pv.currentTokenIs(tokens.LPAREN) {
 			// Handle nested conditions as a SupportsGroup
-			group := &SupportsGroup{}
+			group := &SupportsGroup{Position: pos}
 			for !pv.currentTokenIs(tokens.RPAREN) && !pv.currentTokenIs(tokens.EOF) {
 				condition := pv.parseSupportsCondition()
 				if condition != nil {
@@ -169,12 +186,15 @@ func (pv *ParseVisitor) parseSupportsCondition() SupportsCondition {
 				if pv.currentTokenIs(tokens.IDENT) {
 					op := string(pv.currentToken.Literal)
 					if op == "and" || op == "or" {
-						group.Conditions = append(group.Conditions, &SupportsOperator{Operator: op})
+						operator := &SupportsOperator{Position: pv.pos(), Operator: op}
 						pv.advance()
+						pv.endPos(&operator.Position)
+						group.Conditions = append(group.Conditions, operator)
 					}
 				}
 			}
 			pv.consume(tokens.RPAREN, "Expected ')' to close group condition")
+			pv.endPos(&group.Position)
 			return group
 		} else if pv.currentTokenIs(tokens.IDENT) && pv.nextTokenIs(tokens.LPAREN) {
 			condition := pv.parseSupportsFunction()
@@ -193,6 +213,7 @@ func (pv *ParseVisitor) parseSupportsCondition() SupportsCondition {
 }
 
 func (pv *ParseVisitor) parseSupportsFunction() SupportsCondition {
+	pos := pv.pos()
 	name := pv.currentToken.Literal
 	pv.advance() // Consume function name
 
@@ -217,12 +238,15 @@ func (pv *ParseVisitor) parseSupportsFunction() SupportsCondition {
 		pv.advance()
 	}
 
-	return &SupportsFunction{Name: name, Args: args}
+	function := &SupportsFunction{Position: pos, Name: name, Args: args}
+	pv.endPos(&function.Position)
+	return function
 }
 
 func (pv *ParseVisitor) parseSupportsDeclaration() SupportsCondition {
 	declaration := &SupportsDecleration{
-		Key: pv.currentToken.Literal,
+		Position: pv.pos(),
+		Key:      pv.currentToken.Literal,
 	}
 	pv.advance() // consume the property
 	if !pv.currentTokenIs(tokens.COLON) {
@@ -231,6 +255,7 @@ func (pv *ParseVisitor) parseSupportsDeclaration() SupportsCondition {
 	}
 	pv.advance()
 	declaration.Value = append(declaration.Value, pv.parseValue())
+	pv.endPos(&declaration.Position)
 
 	return declaration
 }
@@ -22,6 +22,8 @@ func init() {
 var _ Node = (*KeyframesAtRule)(nil)
 
 type KeyframesAtRule struct {
+	Position
+
 	WebKitPrefix bool
 	Name         []byte
 	Stops        []KeyframeStop
@@ -47,6 +49,8 @@ func (k *KeyframesAtRule) String() string {
 }
 
 type KeyframeStop struct {
+	Position
+
 	Stops []Value // Could be percentages or "from"/"to"
 	Rules []Node  // Declarations for this keyframe stop
 }
@@ -98,6 +102,8 @@ func visitKeyframesAtRule(pv *ParseVisitor, node AtRule) {
 
 func visitKeyframeStop(pv *ParseVisitor, node Node) {
 	ks := node.(*KeyframeStop)
+	ks.Position = pv.pos()
+	defer pv.endPos(&ks.Position)
 	for !pv.currentTokenIs(tokens.LBRACE) && !pv.currentTokenIs(tokens.EOF) {
 		switch pv.currentToken.Type {
 		case tokens.IDENT, tokens.NUMBER:
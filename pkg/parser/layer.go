@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+const (
+	Layer AtType = "layer"
+)
+
+func init() {
+	RegisterAt(Layer, visitLayerAtRule, func() AtRule { return &LayerAtRule{} })
+}
+
+var _ Node = (*LayerAtRule)(nil)
+
+// LayerAtRule is either a block, @layer name { ... }, or a statement declaring the order of
+// layers, @layer a, b;
+type LayerAtRule struct {
+	Position
+
+	// Names are the layer names. A block has at most one, and none when the layer is anonymous
+	Names       [][]byte
+	IsStatement bool
+	Rules       []Node
+}
+
+func (l *LayerAtRule) Type() NodeType { return NodeAtRule }
+func (l *LayerAtRule) AtType() AtType { return Layer }
+func (l *LayerAtRule) String() string {
+	var sb strings.Builder
+	sb.WriteString("LayerAtRule{\n")
+	sb.WriteString("  Names: [")
+	for i, name := range l.Names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(fmt.Sprintf("%q", name))
+	}
+	sb.WriteString("],\n")
+	sb.WriteString(fmt.Sprintf("  IsStatement: %v,\n", l.IsStatement))
+	if len(l.Rules) > 0 {
+		sb.WriteString("  Rules: [\n")
+		for _, rule := range l.Rules {
+			sb.WriteString(indentLines(rule.String(), 4))
+			sb.WriteString(",\n")
+		}
+		sb.WriteString("  ]\n")
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func visitLayerAtRule(pv *ParseVisitor, node AtRule) {
+	l := node.(*LayerAtRule)
+	pv.advance() // Consume 'layer'
+
+	for !pv.currentTokenIs(tokens.LBRACE) && !pv.currentTokenIs(tokens.SEMICOLON) && !pv.currentTokenIs(tokens.EOF) {
+		if !pv.currentTokenIs(tokens.IDENT) {
+			pv.addError("Expected layer name", pv.currentToken)
+			pv.skipToNextSemicolonOrBrace()
+			return
+		}
+		l.Names = append(l.Names, pv.parseLayerName())
+		if pv.currentTokenIs(tokens.COMMA) {
+			pv.advance() // Consume ','
+		}
+	}
+
+	if pv.currentTokenIs(tokens.SEMICOLON) {
+		l.IsStatement = true
+		pv.advance() // Consume ';'
+		return
+	}
+	if !pv.consume(tokens.LBRACE, "Expected '{' or ';' after @layer") {
+		return
+	}
+	if len(l.Names) > 1 {
+		pv.addError("Expected a single layer name before a @layer block", pv.currentToken)
+	}
+
+	l.Rules = pv.parseRuleBlock("layer")
+}
+
+// parseLayerName parses a possibly dotted layer name such as framework.base.
+func (pv *ParseVisitor) parseLayerName() []byte {
+	var name []byte
+	name = append(name, pv.currentToken.Literal...)
+	pv.advance() // Consume the first identifier
+	for pv.currentTokenIs(tokens.DOT) && pv.nextTokenIs(tokens.IDENT) {
+		pv.advance() // Consume '.'
+		name = append(name, '.')
+		name = append(name, pv.currentToken.Literal...)
+		pv.advance()
+	}
+	return name
+}
@@ -0,0 +1,70 @@
+package parser
+
+import "testing"
+
+func TestLayerAtRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Stylesheet
+	}{
+		{
+			name:  "Layer statement",
+			input: "@layer reset, framework.base;",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&LayerAtRule{
+						Names:       [][]byte{[]byte("reset"), []byte("framework.base")},
+						IsStatement: true,
+					},
+				},
+			},
+		},
+		{
+			name:  "Named layer block",
+			input: "@layer base { body { margin: 0; } }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&LayerAtRule{
+						Names: [][]byte{[]byte("base")},
+						Rules: []Node{
+							&Selector{
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("body")}}}}}},
+								Rules: []Node{
+									&Declaration{Key: []byte("margin"), Value: []Value{&BasicValue{Value: []byte("0")}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Anonymous layer with nested media",
+			input: "@layer { @media print { .a { color: red; } } }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&LayerAtRule{
+						Rules: []Node{
+							&MediaAtRule{
+								Name: []byte("media"),
+								Query: MediaQuery{
+									Queries: []MediaQueryExpression{{MediaType: []byte("print")}},
+								},
+								Rules: []Node{
+									&Selector{
+										Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("a")}}}}}},
+										Rules: []Node{
+											&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	runTests(t, tests)
+}
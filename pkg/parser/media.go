@@ -18,6 +18,8 @@ func init() {
 var _ Node = (*MediaAtRule)(nil)
 
 type MediaAtRule struct {
+	Position
+
 	Name  []byte
 	Query MediaQuery
 	Rules []Node
@@ -45,6 +47,8 @@ func (m *MediaAtRule) String() string {
 }
 
 type MediaQuery struct {
+	Position
+
 	Queries []MediaQueryExpression
 }
 
@@ -62,6 +66,8 @@ func (mq MediaQuery) String() string {
 }
 
 type MediaQueryExpression struct {
+	Position
+
 	MediaType []byte
 	Not       bool
 	Only      bool
@@ -84,6 +90,8 @@ func (mqe MediaQueryExpression) String() string {
 }
 
 type MediaFeature struct {
+	Position
+
 	Name  []byte
 	Value []byte
 }
@@ -107,33 +115,15 @@ func visitMediaAtRule(pv *ParseVisitor, node AtRule) {
 		return
 	}
 
-	// Parse the content of the media block
-	for !pv.currentTokenIs(tokens.RBRACE) && !pv.currentTokenIs(tokens.EOF) {
-		switch pv.currentToken.Type {
-		case tokens.COMMENT:
-			comment := &Comment{Text: pv.currentToken.Literal}
-			visitComment(pv, comment)
-			m.Rules = append(m.Rules, comment)
-		case tokens.DOT, tokens.HASH, tokens.COLON, tokens.DBLCOLON, tokens.IDENT, tokens.LBRACKET:
-			selector := &Selector{
-				Selectors: make([]SelectorValue, 0),
-				Rules:     make([]Node, 0),
-			}
-			visitSelector(pv, selector)
-			m.Rules = append(m.Rules, selector)
-		default:
-			pv.addError("Unexpected token in media block", pv.currentToken)
-			pv.advance()
-		}
-	}
-
-	pv.consume(tokens.RBRACE, "Expected '}' to close media block")
+	m.Rules = pv.parseRuleBlock("media")
 }
 
 func (pv *ParseVisitor) parseMediaQuery() *MediaQuery {
 	mediaQuery := &MediaQuery{
-		Queries: make([]MediaQueryExpression, 0),
+		Position: pv.pos(),
+		Queries:  make([]MediaQueryExpression, 0),
 	}
+	defer pv.endPos(&mediaQuery.Position)
 
 	for !pv.currentTokenIs(tokens.LBRACE) && !pv.currentTokenIs(tokens.EOF) {
 		expr := pv.parseMediaQueryExpression()
@@ -151,6 +141,7 @@ func (pv *ParseVisitor) parseMediaQuery() *MediaQuery {
 
 func (pv *ParseVisitor) parseMediaQueryExpression() MediaQueryExpression {
 	expr := MediaQueryExpression{
+		Position: pv.pos(),
 		Features: make([]MediaFeature, 0),
 	}
 
@@ -180,16 +171,16 @@ func (pv *ParseVisitor) parseMediaQueryExpression() MediaQueryExpression {
 		}
 	}
 
+	pv.endPos(&expr.Position)
 	return expr
 }
 
 func (pv *ParseVisitor) parseMediaFeature() *MediaFeature {
+	feature := &MediaFeature{Position: pv.pos()}
 	if !pv.consume(tokens.LPAREN, "Expected '(' for media feature") {
 		return nil
 	}
 
-	feature := &MediaFeature{}
-
 	if !pv.currentTokenIs(tokens.IDENT) {
 		pv.addError("Expected media feature name", pv.currentToken)
 		pv.skipToNextRule()
@@ -207,7 +198,7 @@ func (pv *ParseVisitor) parseMediaFeature() *MediaFeature {
 
 			// Handle units like 'px'
 			if pv.currentTokenIs(tokens.IDENT) || pv.currentTokenIs(tokens.PERCENTAGE) {
-				feature.Value = append(feature.Value, pv.currentToken.Literal...)
+				feature.Value = concat(feature.Value, pv.currentToken.Literal)
 				pv.advance()
 			}
 		} else {
@@ -218,6 +209,7 @@ func (pv *ParseVisitor) parseMediaFeature() *MediaFeature {
 	if !pv.consume(tokens.RPAREN, "Expected ')' to close media feature") {
 		return nil
 	}
+	pv.endPos(&feature.Position)
 
 	return feature
 }
@@ -1,4 +1,3 @@
-
 package parser
 
 import "testing"
@@ -25,7 +24,7 @@ func TestMediaQueries(t *testing.T) {
 						},
 						Rules: []Node{
 							&Selector{
-								Selectors: []SelectorValue{{Type: Element, Value: []byte("body")}},
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("body")}}}}}},
 								Rules: []Node{
 									&Declaration{Key: []byte("font-size"), Value: []Value{&BasicValue{Value: []byte("16px")}}},
 								},
@@ -53,7 +52,7 @@ func TestMediaQueries(t *testing.T) {
 						},
 						Rules: []Node{
 							&Selector{
-								Selectors: []SelectorValue{{Type: Class, Value: []byte(".container")}},
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("container")}}}}}},
 								Rules: []Node{
 									&Declaration{Key: []byte("width"), Value: []Value{&BasicValue{Value: []byte("100%")}}},
 								},
@@ -83,7 +82,7 @@ func TestMediaQueries(t *testing.T) {
 						},
 						Rules: []Node{
 							&Selector{
-								Selectors: []SelectorValue{{Type: Class, Value: []byte(".sidebar")}},
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("sidebar")}}}}}},
 								Rules: []Node{
 									&Declaration{Key: []byte("display"), Value: []Value{&BasicValue{Value: []byte("none")}}},
 								},
@@ -96,4 +95,3 @@ func TestMediaQueries(t *testing.T) {
 	}
 	runTests(t, tests)
 }
-
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+const (
+	Namespace AtType = "namespace"
+)
+
+func init() {
+	RegisterAt(Namespace, visitNamespaceAtRule, func() AtRule { return &NamespaceAtRule{} })
+}
+
+// NamespaceAtRule declares the namespace that a prefix in selectors refers to, or the default
+// namespace of type selectors when it has no prefix.
+type NamespaceAtRule struct {
+	Position
+
+	// Prefix is nil for the default namespace
+	Prefix []byte
+	// URL is a string or url()
+	URL Value
+}
+
+func (r *NamespaceAtRule) Type() NodeType { return NodeAtRule }
+func (r *NamespaceAtRule) AtType() AtType { return Namespace }
+func (r *NamespaceAtRule) String() string {
+	if r.Prefix == nil {
+		return fmt.Sprintf("NamespaceAtRule{URL: %s}", r.URL)
+	}
+	return fmt.Sprintf("NamespaceAtRule{Prefix: %q, URL: %s}", r.Prefix, r.URL)
+}
+
+// URI returns the namespace URI, from either a string or url().
+func (r *NamespaceAtRule) URI() string {
+	switch v := r.URL.(type) {
+	case *StringValue:
+		return string(v.Value)
+	case *FunctionValue:
+		if len(v.Arguments) == 1 {
+			switch arg := v.Arguments[0].(type) {
+			case *StringValue:
+				return string(arg.Value)
+			case *BasicValue:
+				return string(arg.Value)
+			}
+		}
+	}
+	return ""
+}
+
+func visitNamespaceAtRule(pv *ParseVisitor, node AtRule) {
+	r := node.(*NamespaceAtRule)
+	pv.advance() // Consume 'namespace'
+
+	if pv.currentTokenIs(tokens.IDENT) {
+		r.Prefix = pv.currentToken.Literal
+		pv.advance()
+	}
+
+	if pv.currentTokenIs(tokens.URI) || pv.currentTokenIs(tokens.STRING) {
+		r.URL = pv.parseValue()
+	} else {
+		pv.addError("Expected string or URI after @namespace", pv.currentToken)
+		pv.skipToNextSemicolonOrBrace()
+		return
+	}
+
+	pv.namespaces[string(r.Prefix)] = true
+	pv.consume(tokens.SEMICOLON, "Expected ';' after @namespace rule")
+}
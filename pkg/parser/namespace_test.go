@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+)
+
+func TestNamespaceAtRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Stylesheet
+	}{
+		{
+			name:  "Default namespace",
+			input: `@namespace url(http://www.w3.org/1999/xhtml);`,
+			expected: &Stylesheet{
+				Rules: []Node{
+					&NamespaceAtRule{
+						URL: &FunctionValue{Name: []byte("url"), Arguments: []Value{&BasicValue{Value: []byte("http://www.w3.org/1999/xhtml")}}},
+					},
+				},
+			},
+		},
+		{
+			name: "Prefixed namespace with type selectors",
+			input: `@namespace svg "http://www.w3.org/2000/svg";
+                    svg|rect, *|svg, |p { fill: red; }`,
+			expected: &Stylesheet{
+				Rules: []Node{
+					&NamespaceAtRule{
+						Prefix: []byte("svg"),
+						URL:    &StringValue{Value: []byte("http://www.w3.org/2000/svg")},
+					},
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Namespace: &NamespacePrefix{Name: []byte("svg")}, Name: []byte("rect")}}},
+							}},
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Namespace: &NamespacePrefix{Name: []byte("*")}, Name: []byte("svg")}}},
+							}},
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Namespace: &NamespacePrefix{Name: []byte{}}, Name: []byte("p")}}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("fill"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Universal and attribute selectors",
+			input: `@namespace xlink url("http://www.w3.org/1999/xlink");
+                    * { box-sizing: border-box; }
+                    xlink|*[xlink|href], [*|lang|=en] { color: red; }`,
+			expected: &Stylesheet{
+				Rules: []Node{
+					&NamespaceAtRule{
+						Prefix: []byte("xlink"),
+						URL:    &FunctionValue{Name: []byte("url"), Arguments: []Value{&StringValue{Value: []byte("http://www.w3.org/1999/xlink")}}},
+					},
+					&Selector{
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&UniversalSelector{}}}}}},
+						Rules: []Node{
+							&Declaration{Key: []byte("box-sizing"), Value: []Value{&BasicValue{Value: []byte("border-box")}}},
+						},
+					},
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{
+									&UniversalSelector{Namespace: &NamespacePrefix{Name: []byte("xlink")}},
+									&AttributeSelector{Namespace: &NamespacePrefix{Name: []byte("xlink")}, Name: []byte("href")},
+								}},
+							}},
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{
+									&AttributeSelector{Namespace: &NamespacePrefix{Name: []byte("*")}, Name: []byte("lang"), Matcher: MatchDash, Value: []byte("en")},
+								}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestNamespaces(t *testing.T) {
+	input := `@namespace url(http://www.w3.org/1999/xhtml);
+              @namespace svg "http://www.w3.org/2000/svg";`
+	stylesheet, errors := Parse(lexer.Lex(strings.NewReader(input)))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	namespaces := stylesheet.Namespaces()
+	expected := map[string]string{"": "http://www.w3.org/1999/xhtml", "svg": "http://www.w3.org/2000/svg"}
+	if len(namespaces) != len(expected) {
+		t.Fatalf("Expected %d namespaces, got %v", len(expected), namespaces)
+	}
+	for prefix, uri := range expected {
+		if namespaces[prefix] != uri {
+			t.Errorf("Prefix %q: expected %q, got %q", prefix, uri, namespaces[prefix])
+		}
+	}
+}
+
+func TestUndeclaredNamespacePrefix(t *testing.T) {
+	tests := []string{
+		"svg|rect { fill: red; }",
+		"[xlink|href] { color: red; }",
+		"@namespace svg 'x'; html|a { color: red; }",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, errors := Parse(lexer.Lex(strings.NewReader(input)))
+			if len(errors) != 1 || !strings.Contains(errors[0].Message, "Undeclared namespace prefix") {
+				t.Errorf("Expected an undeclared namespace prefix error, got %v", errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+)
+
+func TestNestedStyleRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Stylesheet
+	}{
+		{
+			name:  "Nesting selector",
+			input: `.card { color: red; &:hover { color: blue; } .theme-dark & { color: white; } }`,
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("card")}}}}}},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+							&Selector{
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{
+									{Selectors: []SimpleSelector{&NestingSelector{}, &PseudoSelector{Name: []byte("hover")}}},
+								}}},
+								Rules: []Node{
+									&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("blue")}}},
+								},
+							},
+							&Selector{
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{
+									{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("theme-dark")}}},
+									{Combinator: Descendant, Selectors: []SimpleSelector{&NestingSelector{}}},
+								}}},
+								Rules: []Node{
+									&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("white")}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Nested rules without the nesting selector",
+			input: `ul { > li { margin: 0; } .item, + p { padding: 0; } a:hover { color: red; } }`,
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("ul")}}}}}},
+						Rules: []Node{
+							&Selector{
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{
+									{Combinator: Child, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("li")}}},
+								}}},
+								Rules: []Node{
+									&Declaration{Key: []byte("margin"), Value: []Value{&BasicValue{Value: []byte("0")}}},
+								},
+							},
+							&Selector{
+								Selectors: SelectorList{
+									{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("item")}}}}},
+									{Compounds: []*CompoundSelector{{Combinator: NextSibling, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("p")}}}}},
+								},
+								Rules: []Node{
+									&Declaration{Key: []byte("padding"), Value: []Value{&BasicValue{Value: []byte("0")}}},
+								},
+							},
+							&Selector{
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{
+									{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("a")}, &PseudoSelector{Name: []byte("hover")}}},
+								}}},
+								Rules: []Node{
+									&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Deeply nested rules",
+			input: `nav { a { &:focus { span { color: red; } } } }`,
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("nav")}}}}}},
+						Rules: []Node{
+							&Selector{
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("a")}}}}}},
+								Rules: []Node{
+									&Selector{
+										Selectors: SelectorList{{Compounds: []*CompoundSelector{
+											{Selectors: []SimpleSelector{&NestingSelector{}, &PseudoSelector{Name: []byte("focus")}}},
+										}}},
+										Rules: []Node{
+											&Selector{
+												Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("span")}}}}}},
+												Rules: []Node{
+													&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Nested conditional at-rules",
+			input: `.a { @media (min-width: 600px) { padding: 0; & .b { margin: 0; } } @supports (display: grid) { display: grid; } }`,
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("a")}}}}}},
+						Rules: []Node{
+							&MediaAtRule{
+								Name: []byte("media"),
+								Query: MediaQuery{
+									Queries: []MediaQueryExpression{
+										{Features: []MediaFeature{{Name: []byte("min-width"), Value: []byte("600px")}}},
+									},
+								},
+								Rules: []Node{
+									&Declaration{Key: []byte("padding"), Value: []Value{&BasicValue{Value: []byte("0")}}},
+									&Selector{
+										Selectors: SelectorList{{Compounds: []*CompoundSelector{
+											{Selectors: []SimpleSelector{&NestingSelector{}}},
+											{Combinator: Descendant, Selectors: []SimpleSelector{&ClassSelector{Name: []byte("b")}}},
+										}}},
+										Rules: []Node{
+											&Declaration{Key: []byte("margin"), Value: []Value{&BasicValue{Value: []byte("0")}}},
+										},
+									},
+								},
+							},
+							&SupportsAtRule{
+								Condition: &SupportsDecleration{Key: []byte("display"), Value: []Value{&BasicValue{Value: []byte("grid")}}},
+								Rules: []Node{
+									&Declaration{Key: []byte("display"), Value: []Value{&BasicValue{Value: []byte("grid")}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestNestedRuleOrDeclaration(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		nested bool
+	}{
+		{name: "Declaration", input: "a { color: red; }", nested: false},
+		{name: "Last declaration without a semicolon", input: "a { color: red }", nested: false},
+		{name: "Declaration with a function", input: "a { background: url(a.png) rgb(0, 0, 0); }", nested: false},
+		{name: "Custom property", input: "a { --x: { b: c }; }", nested: false},
+		{name: "Type selector", input: "a { span { color: red; } }", nested: true},
+		{name: "Pseudo-class after a type selector", input: "a { b:hover { color: red; } }", nested: true},
+		{name: "Pseudo-class with arguments", input: "a { b:not(.c, .d) { color: red; } }", nested: true},
+		{name: "Attribute selector with a semicolon", input: "a { b[title=';'] { color: red; } }", nested: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := Parse(lexer.Lex(strings.NewReader(tt.input)))
+			rules := result.Rules[0].(*Selector).Rules
+			if len(rules) == 0 {
+				t.Fatalf("Expected a rule in the block of %q", tt.input)
+			}
+			if _, nested := rules[0].(*Selector); nested != tt.nested {
+				t.Errorf("Expected nested rule %v, got %T", tt.nested, rules[0])
+			}
+		})
+	}
+}
+
+func TestNestingErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Disallowed at-rule", input: "a { @font-face { font-family: x; } color: red; }"},
+		{name: "Combinator without a selector", input: "a { > { color: red; } color: red; }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, errors := Parse(lexer.Lex(strings.NewReader(tt.input)))
+			if len(errors) == 0 {
+				t.Errorf("Expected an error for %q", tt.input)
+			}
+			rules := result.Rules[0].(*Selector).Rules
+			last, ok := rules[len(rules)-1].(*Declaration)
+			if !ok || string(last.Key) != "color" {
+				t.Errorf("Expected the block to be recovered, got %v", rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+)
+
+func TestStylesheetOutlivesLaterLex(t *testing.T) {
+	first, errors := Parse(lexer.Lex(strings.NewReader(".a { width: 10px; color: red; }")))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+	expected := first.String()
+
+	for i := 0; i < 3; i++ {
+		Parse(lexer.Lex(strings.NewReader("@media (min-width: 100px) { #main > .b { margin: 0 auto; } }")))
+	}
+
+	if got := first.String(); got != expected {
+		t.Errorf("The first stylesheet changed after later parses.\nExpected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestParseDoesNotModifyInput(t *testing.T) {
+	input := ".a { width: 10 %; } @media (min-width: 10 px) { .b { color: red; } } @container (min-width: 10 px) { .c { color: red; } }"
+	content := []byte(input)
+
+	Parse(lexer.LexBytes(content))
+
+	if string(content) != input {
+		t.Errorf("The input was modified by parsing.\nExpected: %s\nGot:      %s", input, content)
+	}
+}
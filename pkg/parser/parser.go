@@ -17,26 +17,120 @@ func (e ParseError) Error() string {
 	return fmt.Sprintf("line %d, column %d: %s (token: %s)\n", e.Line, e.Column, e.Message, e.Token.Type)
 }
 
-func Parse(tokens []tokens.Token) (*Stylesheet, []ParseError) {
+// Position is the location of a node in the original source.
+type Position struct {
+	// Source is the name of the file the node was parsed from, set with WithSource
+	Source string
+	// Line is the 1-based line of the node's first token
+	Line int
+	// Column is the 1-based byte column of the node's first token
+	Column int
+	// Offset is the 0-based byte offset of the node's first token
+	Offset int
+	// End is the byte offset just past the node's last token, so the node spans
+	// source[Offset:End]
+	End int
+}
+
+// Pos returns the position. Nodes that embed a Position implement Positioned.
+func (p Position) Pos() Position { return p }
+
+func (p *Position) setPos(pos Position) { *p = pos }
+
+func (p *Position) setEnd(end int) { p.End = end }
+
+// Positioned is implemented by the nodes that record where they start in the original source.
+type Positioned interface {
+	Pos() Position
+}
+
+type ParseOpt func(*parseOptions)
+
+type parseOptions struct {
+	source string
+}
+
+// WithSource sets the name of the file being parsed, which is recorded in the position of each node
+func WithSource(source string) ParseOpt {
+	return func(opts *parseOptions) {
+		opts.source = source
+	}
+}
+
+func Parse(tokens []tokens.Token, opts ...ParseOpt) (*Stylesheet, []ParseError) {
+	return ParseStream(&sliceSource{tokens: tokens}, opts...)
+}
+
+// TokenSource supplies the parser with tokens one at a time, such as a lexer.Scanner. Next must
+// return an EOF token once the tokens are exhausted, and keep returning it.
+type TokenSource interface {
+	Next() tokens.Token
+}
+
+// ParseStream parses the tokens of a source as they are needed, so that the tokens of a large
+// stylesheet never have to be held at once.
+//
+// Parameters:
+// - input: The source of the tokens, read until it returns an EOF token.
+// - opts: Options such as WithSource.
+//
+// Returns:
+// - The parsed stylesheet.
+// - The errors found while parsing. Errors reading the input are reported by the source, such
+// as by lexer.Scanner's Err.
+func ParseStream(input TokenSource, opts ...ParseOpt) (*Stylesheet, []ParseError) {
+	options := &parseOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	stylesheet := &Stylesheet{Rules: make([]Node, 0)}
-	visitor := NewParseVisitor(tokens)
+	visitor := newParseVisitor(input)
+	visitor.source = options.source
 	visitStylesheet(visitor, stylesheet)
 	return stylesheet, visitor.errors
 }
 
+// sliceSource supplies the tokens of a slice.
+type sliceSource struct {
+	tokens   []tokens.Token
+	position int
+}
+
+func (s *sliceSource) Next() tokens.Token {
+	if s.position >= len(s.tokens) {
+		return tokens.Token{Type: tokens.EOF}
+	}
+	tok := s.tokens[s.position]
+	s.position++
+	return tok
+}
+
 type ParseVisitor struct {
-	tokens       []tokens.Token
-	position     int
+	input        TokenSource
 	currentToken tokens.Token
 	nextToken    tokens.Token
-	errors       []ParseError
+	// lastEnd is the end offset of the last token consumed
+	lastEnd int
+	errors  []ParseError
+	source  string
+	// namespaces holds the prefixes declared by @namespace rules so far
+	namespaces map[string]bool
+	// lookahead holds the tokens read past nextToken by peek
+	lookahead []tokens.Token
+	// nesting is the number of style rules whose blocks enclose the current token
+	nesting int
 }
 
 func NewParseVisitor(tokens []tokens.Token) *ParseVisitor {
+	return newParseVisitor(&sliceSource{tokens: tokens})
+}
+
+func newParseVisitor(input TokenSource) *ParseVisitor {
 	pv := &ParseVisitor{
-		tokens:   tokens,
-		position: 0,
-		errors:   make([]ParseError, 0),
+		input:      input,
+		errors:     make([]ParseError, 0),
+		namespaces: make(map[string]bool),
 	}
 	pv.advance() // Load the first token
 	pv.advance() // Load the second token (now in nextToken)
@@ -44,13 +138,38 @@ func NewParseVisitor(tokens []tokens.Token) *ParseVisitor {
 }
 
 func (pv *ParseVisitor) advance() {
+	pv.lastEnd = pv.currentToken.End
 	pv.currentToken = pv.nextToken
-	if pv.position < len(pv.tokens) {
-		pv.nextToken = pv.tokens[pv.position]
-		pv.position++
-	} else {
-		pv.nextToken = tokens.Token{Type: tokens.EOF}
+	if len(pv.lookahead) > 0 {
+		pv.nextToken = pv.lookahead[0]
+		pv.lookahead = pv.lookahead[1:]
+		return
 	}
+	pv.nextToken = pv.input.Next()
+}
+
+// peek returns the token i positions after nextToken, reading ahead of the input as needed.
+func (pv *ParseVisitor) peek(i int) tokens.Token {
+	for len(pv.lookahead) <= i {
+		pv.lookahead = append(pv.lookahead, pv.input.Next())
+	}
+	return pv.lookahead[i]
+}
+
+// pos returns the position of the current token.
+func (pv *ParseVisitor) pos() Position {
+	return Position{
+		Source: pv.source,
+		Line:   pv.currentToken.Line,
+		Column: pv.currentToken.Column,
+		Offset: pv.currentToken.Offset,
+	}
+}
+
+// endPos records the end of the last consumed token as the end of the node. A node that
+// consumed no tokens, such as an empty media query, spans nothing.
+func (pv *ParseVisitor) endPos(p *Position) {
+	p.setEnd(max(pv.lastEnd, p.Offset))
 }
 
 func (pv *ParseVisitor) currentTokenIs(tokenType tokens.TokenType) bool {
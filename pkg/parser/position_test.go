@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+)
+
+func TestNodeSpans(t *testing.T) {
+	input := "/* head */\n.a > b {\n  color: red;\n  margin: 0 auto\n}\n@media print {\n  .c { color: black; }\n}\n"
+	stylesheet, errors := Parse(lexer.LexBytes([]byte(input)), WithSource("main.css"))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	selector := stylesheet.Rules[1].(*Selector)
+	media := stylesheet.Rules[2].(*MediaAtRule)
+	tests := []struct {
+		name   string
+		node   Positioned
+		text   string
+		line   int
+		column int
+	}{
+		{"Comment", stylesheet.Rules[0].(*Comment), "/* head */", 1, 1},
+		{"Selector", selector, ".a > b {\n  color: red;\n  margin: 0 auto\n}", 2, 1},
+		{"Declaration", selector.Rules[0].(*Declaration), "color: red;", 3, 3},
+		{"Declaration without a semicolon", selector.Rules[1].(*Declaration), "margin: 0 auto", 4, 3},
+		{"At-rule", media, "@media print {\n  .c { color: black; }\n}", 6, 1},
+		{"Nested selector", media.Rules[0].(*Selector), ".c { color: black; }", 7, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := tt.node.Pos()
+			if got := input[pos.Offset:pos.End]; got != tt.text {
+				t.Errorf("Expected the span %q, got %q", tt.text, got)
+			}
+			if pos.Line != tt.line || pos.Column != tt.column || pos.Source != "main.css" {
+				t.Errorf("Expected main.css:%d:%d, got %s:%d:%d", tt.line, tt.column, pos.Source, pos.Line, pos.Column)
+			}
+		})
+	}
+}
+
+func TestNodeSpansFromScanner(t *testing.T) {
+	input := strings.Repeat(".a { color: red; }\n", 50) + "#last { margin: 0; }"
+	stylesheet, _ := ParseStream(lexer.NewScanner(strings.NewReader(input), lexer.WithChunkSize(32)))
+
+	last := stylesheet.Rules[len(stylesheet.Rules)-1].(*Selector)
+	if got := input[last.Offset:last.End]; got != "#last { margin: 0; }" {
+		t.Errorf("Expected the span of the last rule, got %q", got)
+	}
+}
+
+func TestValueSpans(t *testing.T) {
+	input := "a {\n  margin: 1.5em -2px;\n  font: 'x' rgb(0, 0, 0);\n  background: url( 'a.png' ) url(b.png);\n}"
+	stylesheet, errors := Parse(lexer.LexBytes([]byte(input)), WithSource("main.css"))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	rules := stylesheet.Rules[0].(*Selector).Rules
+	margin := rules[0].(*Declaration).Value
+	font := rules[1].(*Declaration).Value
+	background := rules[2].(*Declaration).Value
+	tests := []struct {
+		name   string
+		node   Positioned
+		text   string
+		line   int
+		column int
+	}{
+		{"Dimension", margin[0], "1.5em", 2, 11},
+		{"Negative dimension", margin[1], "-2px", 2, 17},
+		{"String", font[0], "'x'", 3, 9},
+		{"Function", font[1], "rgb(0, 0, 0)", 3, 13},
+		{"Function argument", font[1].(*FunctionValue).Arguments[2], "0", 3, 20},
+		{"Url", background[0], "url( 'a.png' )", 4, 15},
+		{"Quoted url argument", background[0].(*FunctionValue).Arguments[0], " 'a.png' ", 4, 19},
+		{"Url argument", background[1].(*FunctionValue).Arguments[0], "b.png", 4, 34},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := tt.node.Pos()
+			if got := input[pos.Offset:pos.End]; got != tt.text {
+				t.Errorf("Expected the span %q, got %q", tt.text, got)
+			}
+			if pos.Line != tt.line || pos.Column != tt.column || pos.Source != "main.css" {
+				t.Errorf("Expected main.css:%d:%d, got %s:%d:%d", tt.line, tt.column, pos.Source, pos.Line, pos.Column)
+			}
+		})
+	}
+}
+
+func TestSelectorSpans(t *testing.T) {
+	input := "@namespace svg 'x';\nul > li.item#main, svg|rect[data-x='1' i]::before {}\n.a { > b & :not(.c, *) {} }"
+	stylesheet, errors := Parse(lexer.LexBytes([]byte(input)), WithSource("main.css"))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	list := stylesheet.Rules[1].(*Selector).Selectors
+	li := list[0].Compounds[1]
+	rect := list[1].Compounds[0]
+	nested := stylesheet.Rules[2].(*Selector).Rules[0].(*Selector).Selectors[0]
+	not := nested.Compounds[2].Selectors[0].(*PseudoSelector)
+	tests := []struct {
+		name   string
+		node   Positioned
+		text   string
+		line   int
+		column int
+	}{
+		{"Complex selector", list[0], "ul > li.item#main", 2, 1},
+		{"Compound selector", li, "li.item#main", 2, 6},
+		{"Type selector", li.Selectors[0], "li", 2, 6},
+		{"Class selector", li.Selectors[1], ".item", 2, 8},
+		{"ID selector", li.Selectors[2], "#main", 2, 13},
+		{"Namespaced type selector", rect.Selectors[0], "svg|rect", 2, 20},
+		{"Attribute selector", rect.Selectors[1], "[data-x='1' i]", 2, 28},
+		{"Pseudo-element", rect.Selectors[2], "::before", 2, 42},
+		{"Relative selector", nested, "> b & :not(.c, *)", 3, 6},
+		{"Nesting selector", nested.Compounds[1].Selectors[0], "&", 3, 10},
+		{"Pseudo-class", not, ":not(.c, *)", 3, 12},
+		{"Pseudo-class argument", not.Selectors[1].Compounds[0].Selectors[0], "*", 3, 21},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := tt.node.Pos()
+			if got := input[pos.Offset:pos.End]; got != tt.text {
+				t.Errorf("Expected the span %q, got %q", tt.text, got)
+			}
+			if pos.Line != tt.line || pos.Column != tt.column || pos.Source != "main.css" {
+				t.Errorf("Expected main.css:%d:%d, got %s:%d:%d", tt.line, tt.column, pos.Source, pos.Line, pos.Column)
+			}
+		})
+	}
+}
+
+func TestAtRuleSpans(t *testing.T) {
+	input := " @media screen and (min-width: 600px) { }\n" +
+		"@container card (min-width: 400px) { }\n" +
+		"@supports not (display: grid) and (selector(a > b)) { }\n" +
+		"@supports ((display: flex) or (display: grid)) { }\n" +
+		"@keyframes spin { from { opacity: 0; } 50%, to { opacity: 1; } }\n" +
+		"@font-feature-values Font { @swash { fancy: 1; } }\n"
+	stylesheet, errors := Parse(lexer.LexBytes([]byte(input)), WithSource("main.css"))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	media := stylesheet.Rules[0].(*MediaAtRule)
+	container := stylesheet.Rules[1].(*ContainerAtRule)
+	supports := stylesheet.Rules[2].(*SupportsAtRule).Condition.(*SupportsGroup)
+	grouped := stylesheet.Rules[3].(*SupportsAtRule).Condition.(*SupportsGroup)
+	keyframes := stylesheet.Rules[4].(*KeyframesAtRule)
+	fontFeatureValues := stylesheet.Rules[5].(*FontFeatureValuesAtRule)
+	tests := []struct {
+		name   string
+		node   Positioned
+		text   string
+		line   int
+		column int
+	}{
+		{"Stylesheet", stylesheet, input, 1, 1},
+		{"Media query", media.Query, "screen and (min-width: 600px)", 1, 9},
+		{"Media query expression", media.Query.Queries[0], "screen and (min-width: 600px)", 1, 9},
+		{"Media feature", media.Query.Queries[0].Features[0], "(min-width: 600px)", 1, 20},
+		{"Container query", container.Query, "(min-width: 400px)", 2, 17},
+		{"Container condition", container.Query.Conditions[0], "(min-width: 400px)", 2, 17},
+		{"Container feature", container.Query.Conditions[0].Features[0], "min-width: 400px", 2, 18},
+		{"Supports condition", supports, "not (display: grid) and (selector(a > b))", 3, 11},
+		{"Supports negation", supports.Conditions[0], "not (display: grid)", 3, 11},
+		{"Supports declaration", supports.Conditions[0].(*SupportsNot).Condition, "display: grid", 3, 16},
+		{"Supports operator", supports.Conditions[1], "and", 3, 31},
+		{"Supports function", supports.Conditions[2], "selector(a > b)", 3, 36},
+		{"Supports group", grouped, "((display: flex) or (display: grid))", 4, 11},
+		{"Keyframe stop", keyframes.Stops[0], "from { opacity: 0; }", 5, 19},
+		{"Keyframe stop list", keyframes.Stops[1], "50%, to { opacity: 1; }", 5, 40},
+		{"Font feature values block", fontFeatureValues.Blocks[0], "@swash { fancy: 1; }", 6, 29},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := tt.node.Pos()
+			if got := input[pos.Offset:pos.End]; got != tt.text {
+				t.Errorf("Expected the span %q, got %q", tt.text, got)
+			}
+			if pos.Line != tt.line || pos.Column != tt.column || pos.Source != "main.css" {
+				t.Errorf("Expected main.css:%d:%d, got %s:%d:%d", tt.line, tt.column, pos.Source, pos.Line, pos.Column)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 
@@ -18,7 +19,9 @@ func init() {
 var _ Node = (*Selector)(nil)
 
 type Selector struct {
-	Selectors []SelectorValue
+	Position
+
+	Selectors SelectorList
 
 	// Comment
 	// At Rules
@@ -33,7 +36,7 @@ func (s *Selector) String() string {
 	sb.WriteString("Selector{\n")
 	sb.WriteString("  Selectors: [\n")
 	for _, sel := range s.Selectors {
-		sb.WriteString("    " + sel.String() + ",\n")
+		sb.WriteString(indentLines(sel.String(), 4) + ",\n")
 	}
 	sb.WriteString("  ]\n")
 	if len(s.Rules) > 0 {
@@ -49,6 +52,74 @@ func (s *Selector) String() string {
 	return sb.String()
 }
 
+// SelectorList is a comma separated list of selectors, such as "h1, h2 > a". It matches an
+// element that any of its selectors match.
+type SelectorList []*ComplexSelector
+
+// ComplexSelector is a sequence of compound selectors joined by combinators, such as "ul > li a".
+type ComplexSelector struct {
+	Position
+
+	Compounds []*CompoundSelector
+}
+
+func (c *ComplexSelector) String() string {
+	var sb strings.Builder
+	sb.WriteString("ComplexSelector{\n")
+	for _, compound := range c.Compounds {
+		sb.WriteString("  " + compound.String() + "\n")
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// inline returns the selector list on a single line, for nesting in the String of a selector.
+func (l SelectorList) inline() string {
+	complexes := make([]string, len(l))
+	for i, complex := range l {
+		compounds := make([]string, len(complex.Compounds))
+		for j, compound := range complex.Compounds {
+			compounds[j] = compound.String()
+		}
+		complexes[i] = "[" + strings.Join(compounds, " ") + "]"
+	}
+	return "[" + strings.Join(complexes, ", ") + "]"
+}
+
+// CombinatorType is the relationship between an element and the element matched by the
+// compound selector before it.
+type CombinatorType string
+
+const (
+	NoCombinator      CombinatorType = ""
+	Descendant        CombinatorType = " "
+	Child             CombinatorType = ">"
+	NextSibling       CombinatorType = "+"
+	SubsequentSibling CombinatorType = "~"
+)
+
+// CompoundSelector is a sequence of simple selectors that all match the same element, such as
+// "a.external:hover".
+type CompoundSelector struct {
+	Position
+
+	// Combinator joins the compound selector to the one before it. It is NoCombinator for the
+	// first compound selector of a complex selector.
+	Combinator CombinatorType
+	Selectors  []SimpleSelector
+}
+
+func (c *CompoundSelector) String() string {
+	parts := make([]string, len(c.Selectors))
+	for i, sel := range c.Selectors {
+		parts[i] = sel.String()
+	}
+	if c.Combinator == NoCombinator {
+		return fmt.Sprintf("{Selectors: [%s]}", strings.Join(parts, ", "))
+	}
+	return fmt.Sprintf("{Combinator: %q, Selectors: [%s]}", c.Combinator, strings.Join(parts, ", "))
+}
+
 type SelectorType int
 
 const (
@@ -57,36 +128,286 @@ const (
 	ID
 	Attribute
 	Pseudo
-	Combinator
+	Universal
+	Nesting
 )
 
-type SelectorValue struct {
-	Type  SelectorType
-	Value []byte
+// SimpleSelector is a single condition on an element within a compound selector.
+type SimpleSelector interface {
+	Positioned
+	SelectorType() SelectorType
+	String() string
 }
 
-func (sv SelectorValue) String() string {
-	return fmt.Sprintf("{Type: %s, Value: %q}", selectorTypeToString(sv.Type), sv.Value)
+var (
+	_ SimpleSelector = (*TypeSelector)(nil)
+	_ SimpleSelector = (*UniversalSelector)(nil)
+	_ SimpleSelector = (*NestingSelector)(nil)
+	_ SimpleSelector = (*ClassSelector)(nil)
+	_ SimpleSelector = (*IDSelector)(nil)
+	_ SimpleSelector = (*AttributeSelector)(nil)
+	_ SimpleSelector = (*PseudoSelector)(nil)
+)
+
+// NamespacePrefix is the namespace before the '|' of a type, universal or attribute selector,
+// such as "svg" in "svg|rect". Name is "*" for any namespace and empty for no namespace.
+type NamespacePrefix struct {
+	Name []byte
+}
+
+// qualify returns the name with the prefix, if there is one.
+func (n *NamespacePrefix) qualify(name []byte) string {
+	if n == nil {
+		return string(name)
+	}
+	return string(n.Name) + "|" + string(name)
+}
+
+// TypeSelector matches elements by their tag name, such as "div". Namespace is nil when no
+// prefix is given, which matches the default namespace.
+type TypeSelector struct {
+	Position
+
+	Namespace *NamespacePrefix
+	Name      []byte
+}
+
+func (t *TypeSelector) SelectorType() SelectorType { return Element }
+func (t *TypeSelector) String() string {
+	return fmt.Sprintf("Type(%q)", t.Namespace.qualify(t.Name))
+}
+
+// UniversalSelector matches any element, "*", optionally in a namespace.
+type UniversalSelector struct {
+	Position
+
+	Namespace *NamespacePrefix
+}
+
+func (u *UniversalSelector) SelectorType() SelectorType { return Universal }
+func (u *UniversalSelector) String() string {
+	if u.Namespace == nil {
+		return "Universal"
+	}
+	return fmt.Sprintf("Universal(%q)", u.Namespace.qualify([]byte("*")))
+}
+
+// NestingSelector is "&" in a nested style rule, which stands for the elements matched by the
+// selectors of the enclosing rule. Outside of a nested rule it matches the same as :scope.
+type NestingSelector struct {
+	Position
+}
+
+func (n *NestingSelector) SelectorType() SelectorType { return Nesting }
+func (n *NestingSelector) String() string             { return "Nesting" }
+
+// ClassSelector matches elements with a class, such as ".nav". Name excludes the '.'.
+type ClassSelector struct {
+	Position
+
+	Name []byte
+}
+
+func (c *ClassSelector) SelectorType() SelectorType { return Class }
+func (c *ClassSelector) String() string {
+	return fmt.Sprintf("Class(%q)", c.Name)
+}
+
+// IDSelector matches the element with an id, such as "#main". Name excludes the '#'.
+type IDSelector struct {
+	Position
+
+	Name []byte
+}
+
+func (i *IDSelector) SelectorType() SelectorType { return ID }
+func (i *IDSelector) String() string {
+	return fmt.Sprintf("ID(%q)", i.Name)
+}
+
+// AttributeMatcher is the operator of an attribute selector that compares the attribute's value.
+type AttributeMatcher string
+
+const (
+	// MatchExists matches elements that have the attribute, whatever its value
+	MatchExists    AttributeMatcher = ""
+	MatchEquals    AttributeMatcher = "="
+	MatchIncludes  AttributeMatcher = "~="
+	MatchDash      AttributeMatcher = "|="
+	MatchPrefix    AttributeMatcher = "^="
+	MatchSuffix    AttributeMatcher = "$="
+	MatchSubstring AttributeMatcher = "*="
+)
+
+// AttributeModifier sets how an attribute selector compares the case of values.
+type AttributeModifier string
+
+const (
+	// NoModifier compares values with the case sensitivity of the document language
+	NoModifier      AttributeModifier = ""
+	CaseInsensitive AttributeModifier = "i"
+	CaseSensitive   AttributeModifier = "s"
+)
+
+// AttributeSelector matches elements by an attribute, such as "[type='text' i]". Namespace is
+// nil when no prefix is given, which matches attributes without a namespace.
+type AttributeSelector struct {
+	Position
+
+	Namespace *NamespacePrefix
+	Name      []byte
+	Matcher   AttributeMatcher
+	// Value is the value compared by the matcher, without quotes, so that [a=b] and [a="b"]
+	// have the same value. Escapes are kept as written.
+	Value    []byte
+	Modifier AttributeModifier
+}
+
+func (a *AttributeSelector) SelectorType() SelectorType { return Attribute }
+func (a *AttributeSelector) String() string {
+	if a.Matcher == MatchExists {
+		return fmt.Sprintf("Attribute{Name: %q}", a.Namespace.qualify(a.Name))
+	}
+	if a.Modifier == NoModifier {
+		return fmt.Sprintf("Attribute{Name: %q, Matcher: %q, Value: %q}", a.Namespace.qualify(a.Name), a.Matcher, a.Value)
+	}
+	return fmt.Sprintf("Attribute{Name: %q, Matcher: %q, Value: %q, Modifier: %q}",
+		a.Namespace.qualify(a.Name), a.Matcher, a.Value, a.Modifier)
+}
+
+// PseudoSelector is a pseudo-class, such as ":hover" or ":not(.a)", or a pseudo-element, such
+// as "::before".
+type PseudoSelector struct {
+	Position
+
+	Name []byte
+	// Element is set for pseudo-elements, which are written with two colons
+	Element bool
+	// Functional is set when the name is followed by arguments in parentheses
+	Functional bool
+	// Selectors holds the argument of :not(), :is() and :where(), and the relative selectors of
+	// :has(). A relative selector without a leading combinator is a descendant of the anchor.
+	Selectors SelectorList
+	// Nth holds the argument of :nth-child() and the other nth- pseudo-classes
+	Nth *AnPlusB
+	// Arguments holds the text between the parentheses of other functional pseudo-selectors
+	Arguments []byte
+}
+
+func (p *PseudoSelector) SelectorType() SelectorType { return Pseudo }
+func (p *PseudoSelector) String() string {
+	colons := ":"
+	if p.Element {
+		colons = "::"
+	}
+	switch {
+	case p.Selectors != nil:
+		return fmt.Sprintf("Pseudo(%q, %s)", colons+string(p.Name), p.Selectors.inline())
+	case p.Nth != nil:
+		return fmt.Sprintf("Pseudo(%q, %s)", colons+string(p.Name), p.Nth)
+	case p.Functional:
+		return fmt.Sprintf("Pseudo(%q)", colons+string(p.Name)+"("+string(p.Arguments)+")")
+	}
+	return fmt.Sprintf("Pseudo(%q)", colons+string(p.Name))
+}
+
+// ParseSelector parses a selector list on its own, such as the argument of querySelectorAll.
+//
+// Parameters:
+// - input: The tokens of the selector list.
+// - opts: Options such as WithSource.
+//
+// Returns:
+// - The parsed selector list.
+// - The errors found while parsing, including any tokens after the selector list.
+func ParseSelector(input []tokens.Token, opts ...ParseOpt) (SelectorList, []ParseError) {
+	options := &parseOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	visitor := newParseVisitor(&sliceSource{tokens: input})
+	visitor.source = options.source
+	list := visitor.parseSelectorList(nil, false)
+	if !visitor.currentTokenIs(tokens.EOF) {
+		visitor.addError("Unexpected token in selector", visitor.currentToken)
+	}
+	return list, visitor.errors
 }
 
 func visitSelector(pv *ParseVisitor, node Node) {
 	s := node.(*Selector)
-	pv.parseSelector(s)
+	s.Position = pv.pos()
+	defer pv.endPos(&s.Position)
+	// The selectors of a nested style rule are relative to the enclosing rule, as in "> li"
+	s.Selectors = pv.parseSelectorList(&s.Rules, pv.nesting > 0)
+	if !pv.currentTokenIs(tokens.LBRACE) && !pv.currentTokenIs(tokens.EOF) {
+		pv.addError("Unexpected token in selector", pv.currentToken)
+		for !pv.currentTokenIs(tokens.LBRACE) && !pv.currentTokenIs(tokens.EOF) {
+			pv.advance() // Skip the rest of the invalid selector
+		}
+	}
 	if !pv.consume(tokens.LBRACE, "Expected '{' after selector") {
 		return
 	}
+	s.Rules = append(s.Rules, pv.parseStyleBlock("Expected '}' at the end of declaration block")...)
+}
+
+// nestableAtRules are the at-rules allowed in the block of a style rule.
+var nestableAtRules = map[AtType]bool{
+	Media:     true,
+	Supports:  true,
+	Container: true,
+	Layer:     true,
+}
+
+// parseStyleBlock parses the block of a style rule whose opening '{' has been consumed, up to
+// and including the closing '}'. Besides declarations, the block can hold nested style rules and
+// conditional at-rules, whose blocks are parsed the same way.
+//
+// Parameters:
+// - closeError: The error reported when the block is not closed.
+//
+// Returns:
+// - The comments, declarations, nested style rules and at-rules of the block.
+func (pv *ParseVisitor) parseStyleBlock(closeError string) []Node {
+	pv.nesting++
+	defer func() { pv.nesting-- }()
+
+	rules := make([]Node, 0)
 	for !pv.currentTokenIs(tokens.RBRACE) && !pv.currentTokenIs(tokens.EOF) {
-		switch pv.currentToken.Type {
-		case tokens.COMMENT:
+		switch {
+		case pv.currentTokenIs(tokens.COMMENT):
 			comment := &Comment{Text: pv.currentToken.Literal}
 			visitComment(pv, comment)
-			s.Rules = append(s.Rules, comment)
-		case tokens.IDENT:
+			rules = append(rules, comment)
+		case pv.currentTokenIs(tokens.AT):
+			atRule := pv.getAtRule()
+			if atRule == nil {
+				pv.skipToNextRule()
+				continue
+			}
+			at := atRule.(AtRule)
+			if !nestableAtRules[at.AtType()] {
+				pv.addError(fmt.Sprintf("@%s is not allowed in a style rule", at.AtType()), pv.currentToken)
+				visitAt(pv, atRule) // Parse the rule to skip it
+				continue
+			}
+			visitAt(pv, atRule)
+			rules = append(rules, atRule)
+		case pv.startsNestedRule():
+			selector := &Selector{
+				Selectors: SelectorList{},
+				Rules:     make([]Node, 0),
+			}
+			visitSelector(pv, selector)
+			rules = append(rules, selector)
+		case pv.currentTokenIs(tokens.IDENT):
 			declaration := &Declaration{
 				Key: pv.currentToken.Literal,
 			}
 			visitDeclaration(pv, declaration)
-			s.Rules = append(s.Rules, declaration)
+			rules = append(rules, declaration)
 
 			if pv.currentTokenIs(tokens.SEMICOLON) {
 				pv.advance() // Consume ';'
@@ -96,31 +417,141 @@ func visitSelector(pv *ParseVisitor, node Node) {
 			pv.skipToNextSemicolonOrBrace()
 		}
 	}
-	pv.consume(tokens.RBRACE, "Expected '}' at the end of declaration block")
+	pv.consume(tokens.RBRACE, closeError)
+	return rules
+}
+
+// startsNestedRule reports whether the current token starts a nested style rule rather than a
+// declaration. An identifier can start either, such as "color: red" and "a:hover { }", so the
+// tokens are read ahead to whichever of '{', ';' or '}' comes first outside of brackets.
+func (pv *ParseVisitor) startsNestedRule() bool {
+	switch pv.currentToken.Type {
+	case tokens.IDENT:
+	case tokens.GREATER, tokens.PLUS, tokens.TILDE:
+		return true
+	default:
+		return startsCompoundSelector(pv.currentToken.Type)
+	}
+
+	if bytes.HasPrefix(pv.currentToken.Literal, []byte("--")) {
+		return false // Custom properties can hold blocks, as in --x: { a: b }
+	}
+	depth := 0
+	tok := pv.nextToken
+	for i := 0; ; i++ {
+		switch tok.Type {
+		case tokens.LPAREN, tokens.LBRACKET, tokens.FUNCTION:
+			depth++
+		case tokens.RPAREN, tokens.RBRACKET:
+			depth--
+		case tokens.LBRACE:
+			if depth <= 0 {
+				return true
+			}
+		case tokens.SEMICOLON, tokens.RBRACE:
+			if depth <= 0 {
+				return false
+			}
+		case tokens.EOF:
+			return false
+		}
+		tok = pv.peek(i)
+	}
+}
+
+// parseSelectorList parses a comma separated list of complex selectors, stopping at the first
+// token that cannot continue it. Comments between the selectors are appended to comments. The
+// selectors of a relative list can start with a combinator.
+func (pv *ParseVisitor) parseSelectorList(comments *[]Node, relative bool) SelectorList {
+	list := SelectorList{}
+	for {
+		if complex := pv.parseComplexSelector(comments, relative); complex != nil {
+			list = append(list, complex)
+		}
+		if !pv.currentTokenIs(tokens.COMMA) {
+			return list
+		}
+		pv.advance() // Consume ','
+	}
 }
 
-func (pv *ParseVisitor) parseSelector(s *Selector) {
-	for !pv.currentTokenIs(tokens.EOF) && !pv.currentTokenIs(tokens.LBRACE) {
+// parseComplexSelector parses compound selectors joined by combinators. Whitespace between two
+// compound selectors is the descendant combinator.
+func (pv *ParseVisitor) parseComplexSelector(comments *[]Node, relative bool) *ComplexSelector {
+	complex := &ComplexSelector{}
+	combinator := NoCombinator
+	// invalid is set when a compound selector was dropped after reporting an error
+	invalid := false
+	for {
 		switch pv.currentToken.Type {
 		case tokens.COMMENT:
-			// Handle comments in selector definition
 			comment := &Comment{Text: pv.currentToken.Literal}
 			visitComment(pv, comment)
-			s.Rules = append(s.Rules, comment)
-		case tokens.IDENT:
-			s.Selectors = append(s.Selectors, SelectorValue{
-				Type:  Element,
-				Value: pv.currentToken.Literal,
-			})
+			if comments != nil {
+				*comments = append(*comments, comment)
+			}
+			continue
+		case tokens.GREATER, tokens.PLUS, tokens.TILDE:
+			if combinator != NoCombinator || (len(complex.Compounds) == 0 && !relative) {
+				pv.addError("Expected a selector before combinator", pv.currentToken)
+			}
+			if len(complex.Compounds) == 0 && combinator == NoCombinator {
+				complex.Position = pv.pos() // A relative selector starts at its combinator
+			}
+			combinator = CombinatorType(pv.currentToken.Literal)
 			pv.advance()
+			continue
+		}
+
+		if !startsCompoundSelector(pv.currentToken.Type) {
+			break
+		}
+		if combinator == NoCombinator && len(complex.Compounds) > 0 {
+			combinator = Descendant
+		}
+		if len(complex.Compounds) == 0 && combinator == NoCombinator {
+			complex.Position = pv.pos()
+		}
+		compound := pv.parseCompoundSelector()
+		if len(compound.Selectors) == 0 {
+			invalid = true
+			continue
+		}
+		compound.Combinator = combinator
+		complex.Compounds = append(complex.Compounds, compound)
+		combinator = NoCombinator
+	}
+
+	if combinator != NoCombinator && !invalid {
+		pv.addError("Expected a selector after combinator", pv.currentToken)
+	}
+	if len(complex.Compounds) == 0 {
+		if combinator == NoCombinator && !invalid {
+			pv.addError("Expected a selector", pv.currentToken)
+		}
+		return nil
+	}
+	pv.endPos(&complex.Position)
+	return complex
+}
+
+// parseCompoundSelector parses simple selectors up to the next whitespace or combinator.
+func (pv *ParseVisitor) parseCompoundSelector() *CompoundSelector {
+	compound := &CompoundSelector{Position: pv.pos()}
+	for {
+		pos := pv.pos()
+		switch pv.currentToken.Type {
+		case tokens.IDENT, tokens.ASTERISK, tokens.PIPE:
+			if typeSelector := pv.parseTypeSelector(); typeSelector != nil {
+				compound.Selectors = append(compound.Selectors, typeSelector)
+			}
 		case tokens.DOT:
 			if pv.nextTokenIs(tokens.IDENT) {
 				pv.advance() // Consume the dot
-				s.Selectors = append(s.Selectors, SelectorValue{
-					Type:  Class,
-					Value: append([]byte("."), pv.currentToken.Literal...),
-				})
+				class := &ClassSelector{Position: pos, Name: pv.currentToken.Literal}
 				pv.advance() // Consume the identifier
+				pv.endPos(&class.Position)
+				compound.Selectors = append(compound.Selectors, class)
 			} else {
 				pv.addError("Expected identifier after '.'", pv.nextToken)
 				pv.advance() // Skip the dot
@@ -128,100 +559,257 @@ func (pv *ParseVisitor) parseSelector(s *Selector) {
 		case tokens.HASH:
 			if pv.nextTokenIs(tokens.IDENT) {
 				pv.advance() // Consume the hash
-				s.Selectors = append(s.Selectors, SelectorValue{
-					Type:  ID,
-					Value: append([]byte("#"), pv.currentToken.Literal...),
-				})
+				id := &IDSelector{Position: pos, Name: pv.currentToken.Literal}
 				pv.advance() // Consume the identifier
+				pv.endPos(&id.Position)
+				compound.Selectors = append(compound.Selectors, id)
 			} else {
 				pv.addError("Expected identifier after '#'", pv.nextToken)
 				pv.advance() // Skip the hash
 			}
+		case tokens.AMPERSAND:
+			nesting := &NestingSelector{Position: pos}
+			pv.advance()
+			pv.endPos(&nesting.Position)
+			compound.Selectors = append(compound.Selectors, nesting)
+		case tokens.COLOR:
+			// Ids that are valid hex colors, such as #add, are lexed as colors
+			id := &IDSelector{Position: pos, Name: pv.currentToken.Literal[1:]}
+			pv.advance()
+			pv.endPos(&id.Position)
+			compound.Selectors = append(compound.Selectors, id)
 		case tokens.LBRACKET:
-			attrSelector := pv.parseAttributeSelector()
-			if attrSelector != nil {
-				s.Selectors = append(s.Selectors, *attrSelector)
+			if attrSelector := pv.parseAttributeSelector(); attrSelector != nil {
+				compound.Selectors = append(compound.Selectors, attrSelector)
 			}
 		case tokens.COLON, tokens.DBLCOLON:
-			pseudoSelector := pv.parsePseudoSelector()
-			if pseudoSelector != nil {
-				s.Selectors = append(s.Selectors, *pseudoSelector)
-			}
-		case tokens.COMMA, tokens.GREATER, tokens.PLUS, tokens.TILDE:
-			s.Selectors = append(s.Selectors, SelectorValue{
-				Type:  Combinator,
-				Value: pv.currentToken.Literal,
-			})
-			pv.advance()
-		default:
-			pv.addError("Unexpected token in selector", pv.currentToken)
-			pv.advance() // Skip unexpected token
+			if pseudoSelector := pv.parsePseudoSelector(); pseudoSelector != nil {
+				compound.Selectors = append(compound.Selectors, pseudoSelector)
+			}
+		}
+
+		if pv.currentToken.SpaceBefore || !startsCompoundSelector(pv.currentToken.Type) {
+			pv.endPos(&compound.Position)
+			return compound
 		}
 	}
 }
 
-func (pv *ParseVisitor) parseAttributeSelector() *SelectorValue {
-	var attrBuilder strings.Builder
-	attrBuilder.WriteByte('[')
+// startsCompoundSelector reports whether a token of the type can start a compound selector.
+func startsCompoundSelector(tokenType tokens.TokenType) bool {
+	switch tokenType {
+	case tokens.IDENT, tokens.ASTERISK, tokens.PIPE, tokens.DOT, tokens.HASH, tokens.COLOR,
+		tokens.LBRACKET, tokens.COLON, tokens.DBLCOLON, tokens.AMPERSAND:
+		return true
+	}
+	return false
+}
 
-	pv.advance() // Consume '['
-	for !pv.currentTokenIs(tokens.RBRACKET) && !pv.currentTokenIs(tokens.EOF) {
-		attrBuilder.Write(pv.currentToken.Literal)
+// parseTypeSelector parses a type or universal selector with an optional namespace prefix.
+func (pv *ParseVisitor) parseTypeSelector() SimpleSelector {
+	pos := pv.pos()
+	namespace := pv.parseNamespacePrefix()
+	switch pv.currentToken.Type {
+	case tokens.IDENT:
+		typeSelector := &TypeSelector{Position: pos, Namespace: namespace, Name: pv.currentToken.Literal}
+		pv.advance()
+		pv.endPos(&typeSelector.Position)
+		return typeSelector
+	case tokens.ASTERISK:
+		universal := &UniversalSelector{Position: pos, Namespace: namespace}
 		pv.advance()
+		pv.endPos(&universal.Position)
+		return universal
+	default:
+		pv.addError("Expected element name or '*' after namespace prefix", pv.currentToken)
+		return nil
 	}
+}
 
-	if pv.currentTokenIs(tokens.RBRACKET) {
-		attrBuilder.WriteByte(']')
-		pv.advance() // Consume ']'
-		return &SelectorValue{
-			Type:  Attribute,
-			Value: []byte(attrBuilder.String()),
+// parseNamespacePrefix parses a namespace prefix and its '|', returning nil if the current
+// token does not start one.
+func (pv *ParseVisitor) parseNamespacePrefix() *NamespacePrefix {
+	if pv.currentTokenIs(tokens.PIPE) {
+		pv.advance() // Consume '|'
+		return &NamespacePrefix{Name: []byte{}}
+	}
+	if (!pv.currentTokenIs(tokens.IDENT) && !pv.currentTokenIs(tokens.ASTERISK)) ||
+		!pv.nextTokenIs(tokens.PIPE) || pv.nextToken.SpaceBefore {
+		return nil
+	}
+
+	namespace := &NamespacePrefix{Name: pv.currentToken.Literal}
+	pv.checkNamespace(pv.currentToken)
+	pv.advance() // Consume the prefix
+	pv.advance() // Consume '|'
+	return namespace
+}
+
+// checkNamespace reports an error if the token is a prefix that no @namespace rule declared.
+func (pv *ParseVisitor) checkNamespace(prefix tokens.Token) {
+	if prefix.Type == tokens.IDENT && !pv.namespaces[string(prefix.Literal)] {
+		pv.addError(fmt.Sprintf("Undeclared namespace prefix: %s", prefix.Literal), prefix)
+	}
+}
+
+func (pv *ParseVisitor) parseAttributeSelector() *AttributeSelector {
+	attr := &AttributeSelector{Position: pv.pos()}
+	pv.advance() // Consume '['
+	if pv.currentTokenIs(tokens.IDENT) {
+		name := pv.currentToken
+		pv.advance()
+		// "[ns|name]" has a namespace, but "[name|=value]" is the dash matcher
+		if pv.currentTokenIs(tokens.PIPE) && pv.nextTokenIs(tokens.IDENT) &&
+			!pv.currentToken.SpaceBefore && !pv.nextToken.SpaceBefore {
+			attr.Namespace = &NamespacePrefix{Name: name.Literal}
+			pv.checkNamespace(name)
+			pv.advance() // Consume '|'
+		} else {
+			attr.Name = name.Literal
 		}
 	} else {
+		attr.Namespace = pv.parseNamespacePrefix()
+	}
+
+	if attr.Name == nil {
+		if !pv.currentTokenIs(tokens.IDENT) {
+			pv.addError("Expected attribute name", pv.currentToken)
+			pv.skipToClosingBracket()
+			return nil
+		}
+		attr.Name = pv.currentToken.Literal
+		pv.advance()
+	}
+
+	if !pv.currentTokenIs(tokens.RBRACKET) {
+		if !pv.parseAttributeCondition(attr) {
+			pv.skipToClosingBracket()
+			return nil
+		}
+	}
+
+	if !pv.currentTokenIs(tokens.RBRACKET) {
 		pv.addError("Expected closing bracket for attribute selector", pv.currentToken)
+		pv.skipToClosingBracket()
 		return nil
 	}
+	pv.advance() // Consume ']'
+	pv.endPos(&attr.Position)
+	return attr
 }
 
-func (pv *ParseVisitor) parsePseudoSelector() *SelectorValue {
-	pseudo := pv.currentToken.Literal
-	pv.advance() // Consume the colon(s)
+// parseAttributeCondition parses the matcher, value and modifier that follow the name of an
+// attribute selector. It reports whether they were valid.
+func (pv *ParseVisitor) parseAttributeCondition(attr *AttributeSelector) bool {
+	switch pv.currentToken.Type {
+	case tokens.EQUALS:
+		attr.Matcher = MatchEquals
+	case tokens.STARTS_WITH:
+		attr.Matcher = MatchPrefix
+	case tokens.TILDE, tokens.PIPE, tokens.DOLLAR, tokens.ASTERISK:
+		if !pv.nextTokenIs(tokens.EQUALS) || pv.nextToken.SpaceBefore {
+			pv.addError("Expected '=' in attribute matcher", pv.nextToken)
+			return false
+		}
+		attr.Matcher = AttributeMatcher(string(pv.currentToken.Literal) + "=")
+		pv.advance() // Consume the first character of the matcher
+	default:
+		pv.addError("Expected attribute matcher", pv.currentToken)
+		return false
+	}
+	pv.advance() // Consume '='
+
+	switch pv.currentToken.Type {
+	case tokens.IDENT:
+		attr.Value = pv.currentToken.Literal
+	case tokens.STRING:
+		attr.Value = unquote(pv.currentToken.Literal)
+	default:
+		pv.addError("Expected identifier or string as attribute value", pv.currentToken)
+		return false
+	}
+	pv.advance()
+
 	if pv.currentTokenIs(tokens.IDENT) {
-		pseudo = append(pseudo, pv.currentToken.Literal...)
+		switch modifier := AttributeModifier(bytes.ToLower(pv.currentToken.Literal)); modifier {
+		case CaseInsensitive, CaseSensitive:
+			attr.Modifier = modifier
+		default:
+			pv.addError("Unknown attribute selector modifier", pv.currentToken)
+			return false
+		}
+		pv.advance()
+	}
+	return true
+}
+
+// skipToClosingBracket skips the rest of an invalid attribute selector.
+func (pv *ParseVisitor) skipToClosingBracket() {
+	for !pv.currentTokenIs(tokens.RBRACKET) && !pv.currentTokenIs(tokens.EOF) {
 		pv.advance()
+	}
+	if pv.currentTokenIs(tokens.RBRACKET) {
+		pv.advance() // Consume ']'
+	}
+}
 
-		// Check if it's a functional pseudo-class
-		if pv.currentTokenIs(tokens.LPAREN) {
-			pseudo = append(pseudo, pv.currentToken.Literal...)
-			pv.advance() // Consume '('
+func (pv *ParseVisitor) parsePseudoSelector() *PseudoSelector {
+	pseudo := &PseudoSelector{Position: pv.pos(), Element: pv.currentTokenIs(tokens.DBLCOLON)}
+	defer pv.endPos(&pseudo.Position)
+	pv.advance() // Consume the colon(s)
+	if !pv.currentTokenIs(tokens.IDENT) {
+		pv.addError("Expected identifier after pseudo-selector", pv.currentToken)
+		return nil
+	}
+	pseudo.Name = pv.currentToken.Literal
+	pv.advance()
 
-			// Parse the contents of the pseudo-class
-			pseudoContents := pv.parsePseudoClassContents()
-			pseudo = append(pseudo, pseudoContents...)
+	// Check if it's a functional pseudo-class
+	if pv.currentTokenIs(tokens.LPAREN) {
+		pseudo.Functional = true
+		pv.advance() // Consume '('
 
-			if pv.currentTokenIs(tokens.RPAREN) {
-				pseudo = append(pseudo, pv.currentToken.Literal...)
-				pv.advance() // Consume ')'
-			} else {
-				pv.addError("Expected closing parenthesis for pseudo-class", pv.currentToken)
-			}
+		// Parse the contents of the pseudo-class
+		name := strings.ToLower(string(pseudo.Name))
+		switch {
+		case pseudo.Element:
+			pseudo.Arguments = pv.parsePseudoClassContents()
+		case name == "not" || name == "is" || name == "where":
+			pseudo.Selectors = pv.parseSelectorList(nil, false)
+		case name == "has":
+			pseudo.Selectors = pv.parseSelectorList(nil, true)
+		case strings.HasPrefix(name, "nth-"):
+			pseudo.Nth = pv.parseAnPlusB(name == "nth-child" || name == "nth-last-child")
+		default:
+			pseudo.Arguments = pv.parsePseudoClassContents()
 		}
 
-		return &SelectorValue{
-			Type:  Pseudo,
-			Value: pseudo,
+		if !pv.currentTokenIs(tokens.RPAREN) && !pv.currentTokenIs(tokens.EOF) {
+			pv.addError(fmt.Sprintf("Unexpected token in :%s()", pseudo.Name), pv.currentToken)
+			pv.parsePseudoClassContents() // Skip the rest of the arguments
+		}
+		if pv.currentTokenIs(tokens.RPAREN) {
+			pv.advance() // Consume ')'
+		} else {
+			pv.addError("Expected closing parenthesis for pseudo-class", pv.currentToken)
 		}
-	} else {
-		pv.addError("Expected identifier after pseudo-selector", pv.currentToken)
-		return nil
 	}
+
+	return pseudo
 }
 
+// parsePseudoClassContents returns the text of the arguments of a functional pseudo-selector,
+// up to the closing parenthesis.
 func (pv *ParseVisitor) parsePseudoClassContents() []byte {
 	var contents []byte
 	parenthesesCount := 1
 
 	for parenthesesCount > 0 && !pv.currentTokenIs(tokens.EOF) {
+		// Keep the whitespace between arguments, which can be a descendant combinator
+		if pv.currentToken.SpaceBefore && len(contents) > 0 && !pv.currentTokenIs(tokens.RPAREN) {
+			contents = append(contents, ' ')
+		}
+
 		switch pv.currentToken.Type {
 		case tokens.LPAREN:
 			parenthesesCount++
@@ -230,12 +818,6 @@ func (pv *ParseVisitor) parsePseudoClassContents() []byte {
 			if parenthesesCount == 0 {
 				return contents
 			}
-		case tokens.LBRACKET:
-			attributeSelector := pv.parseAttributeSelector()
-			if attributeSelector != nil {
-				contents = append(contents, attributeSelector.Value...)
-			}
-			continue
 		}
 
 		contents = append(contents, pv.currentToken.Literal...)
@@ -20,7 +20,7 @@ func TestBasicSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{{Type: Element, Value: []byte("div")}},
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("div")}}}}}},
 						Rules: []Node{
 							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("blue")}}},
 						},
@@ -34,7 +34,7 @@ func TestBasicSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{{Type: Class, Value: []byte(".highlight")}},
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("highlight")}}}}}},
 						Rules: []Node{
 							&Declaration{Key: []byte("background-color"), Value: []Value{&BasicValue{Value: []byte("yellow")}}},
 						},
@@ -48,7 +48,7 @@ func TestBasicSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{{Type: ID, Value: []byte("#main")}},
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&IDSelector{Name: []byte("main")}}}}}},
 						Rules: []Node{
 							&Declaration{Key: []byte("font-size"), Value: []Value{&BasicValue{Value: []byte("16px")}}},
 						},
@@ -62,7 +62,7 @@ func TestBasicSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{{Type: Attribute, Value: []byte("[type='text']")}},
+						Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&AttributeSelector{Name: []byte("type"), Matcher: MatchEquals, Value: []byte("text")}}}}}},
 						Rules: []Node{
 							&Declaration{Key: []byte("border"), Value: []Value{
 								&BasicValue{Value: []byte("1px")},
@@ -91,9 +91,10 @@ func TestComplexSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Element, Value: []byte("div")},
-							{Type: Class, Value: []byte(".container")},
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("div")}, &ClassSelector{Name: []byte("container")}}},
+							}},
 						},
 						Rules: []Node{
 							&Declaration{Key: []byte("max-width"), Value: []Value{&BasicValue{Value: []byte("1200px")}}},
@@ -108,12 +109,16 @@ func TestComplexSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Element, Value: []byte("h1")},
-							{Type: Combinator, Value: []byte(",")},
-							{Type: Element, Value: []byte("h2")},
-							{Type: Combinator, Value: []byte(",")},
-							{Type: Element, Value: []byte("h3")},
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("h1")}}},
+							}},
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("h2")}}},
+							}},
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("h3")}}},
+							}},
 						},
 						Rules: []Node{
 							&Declaration{Key: []byte("font-family"), Value: []Value{&BasicValue{Value: []byte("sans-serif")}}},
@@ -128,9 +133,11 @@ func TestComplexSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Element, Value: []byte("article")},
-							{Type: Element, Value: []byte("p")},
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("article")}}},
+								{Combinator: Descendant, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("p")}}},
+							}},
 						},
 						Rules: []Node{
 							&Declaration{Key: []byte("line-height"), Value: []Value{&BasicValue{Value: []byte("1.5")}}},
@@ -145,10 +152,11 @@ func TestComplexSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Element, Value: []byte("ul")},
-							{Type: Combinator, Value: []byte(">")},
-							{Type: Element, Value: []byte("li")},
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("ul")}}},
+								{Combinator: Child, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("li")}}},
+							}},
 						},
 						Rules: []Node{
 							&Declaration{Key: []byte("list-style-type"), Value: []Value{&BasicValue{Value: []byte("square")}}},
@@ -165,10 +173,18 @@ func TestComplexSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Class, Value: []byte(".form-select")},
-							{Type: Pseudo, Value: []byte(":not([multiple])")},
-							{Type: Pseudo, Value: []byte(":not([size])")},
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{
+									&ClassSelector{Name: []byte("form-select")},
+									&PseudoSelector{Name: []byte("not"), Functional: true, Selectors: SelectorList{
+										{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&AttributeSelector{Name: []byte("multiple")}}}}},
+									}},
+									&PseudoSelector{Name: []byte("not"), Functional: true, Selectors: SelectorList{
+										{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&AttributeSelector{Name: []byte("size")}}}}},
+									}},
+								}},
+							}},
 						},
 						Rules: []Node{
 							&Declaration{
@@ -187,6 +203,428 @@ func TestComplexSelectors(t *testing.T) {
 	runTests(t, tests)
 }
 
+func TestDescendantCombinator(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Stylesheet
+	}{
+		{
+			name:  "Descendant class",
+			input: ".nav .item { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("nav")}}},
+								{Combinator: Descendant, Selectors: []SimpleSelector{&ClassSelector{Name: []byte("item")}}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Compound classes are not descendants",
+			input: ".nav.item { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("nav")}, &ClassSelector{Name: []byte("item")}}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Whitespace around other combinators",
+			input: "ul  >  li ,  ol li :hover { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("ul")}}},
+								{Combinator: Child, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("li")}}},
+							}},
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("ol")}}},
+								{Combinator: Descendant, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("li")}}},
+								{Combinator: Descendant, Selectors: []SimpleSelector{&PseudoSelector{Name: []byte("hover")}}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Descendant inside a pseudo-class",
+			input: "li:not(.nav .item) { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{
+									&TypeSelector{Name: []byte("li")},
+									&PseudoSelector{Name: []byte("not"), Functional: true, Selectors: SelectorList{
+										{Compounds: []*CompoundSelector{
+											{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("nav")}}},
+											{Combinator: Descendant, Selectors: []SimpleSelector{&ClassSelector{Name: []byte("item")}}},
+										}},
+									}},
+								}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestSelectorTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Stylesheet
+	}{
+		{
+			name:  "Sibling combinators",
+			input: "h1 + p ~ span { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("h1")}}},
+								{Combinator: NextSibling, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("p")}}},
+								{Combinator: SubsequentSibling, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("span")}}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Universal selector in a compound",
+			input: "ul>*.item[data-x] { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("ul")}}},
+								{Combinator: Child, Selectors: []SimpleSelector{
+									&UniversalSelector{},
+									&ClassSelector{Name: []byte("item")},
+									&AttributeSelector{Name: []byte("data-x")},
+								}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Id that is a hex color",
+			input: "#add, a#fff::after { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&IDSelector{Name: []byte("add")}}},
+							}},
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{
+									&TypeSelector{Name: []byte("a")},
+									&IDSelector{Name: []byte("fff")},
+									&PseudoSelector{Name: []byte("after"), Element: true},
+								}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestAttributeSelectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected SimpleSelector
+	}{
+		{name: "Presence", input: "[disabled]", expected: &AttributeSelector{Name: []byte("disabled")}},
+		{name: "Equals identifier", input: "[type=text]", expected: &AttributeSelector{Name: []byte("type"), Matcher: MatchEquals, Value: []byte("text")}},
+		{name: "Equals single quoted", input: "[type='text']", expected: &AttributeSelector{Name: []byte("type"), Matcher: MatchEquals, Value: []byte("text")}},
+		{name: "Equals double quoted", input: `[type="text"]`, expected: &AttributeSelector{Name: []byte("type"), Matcher: MatchEquals, Value: []byte("text")}},
+		{name: "Includes", input: "[class~=nav]", expected: &AttributeSelector{Name: []byte("class"), Matcher: MatchIncludes, Value: []byte("nav")}},
+		{name: "Dash", input: "[lang|=en]", expected: &AttributeSelector{Name: []byte("lang"), Matcher: MatchDash, Value: []byte("en")}},
+		{name: "Prefix", input: "[href^='https']", expected: &AttributeSelector{Name: []byte("href"), Matcher: MatchPrefix, Value: []byte("https")}},
+		{name: "Suffix", input: "[href$='.pdf']", expected: &AttributeSelector{Name: []byte("href"), Matcher: MatchSuffix, Value: []byte(".pdf")}},
+		{name: "Substring", input: "[title*=\"a b\"]", expected: &AttributeSelector{Name: []byte("title"), Matcher: MatchSubstring, Value: []byte("a b")}},
+		{name: "Whitespace inside the brackets", input: "[ type = 'a' ]", expected: &AttributeSelector{Name: []byte("type"), Matcher: MatchEquals, Value: []byte("a")}},
+		{name: "Case-insensitive flag", input: "[type='a' i]", expected: &AttributeSelector{Name: []byte("type"), Matcher: MatchEquals, Value: []byte("a"), Modifier: CaseInsensitive}},
+		{name: "Case-sensitive flag", input: "[type=a S]", expected: &AttributeSelector{Name: []byte("type"), Matcher: MatchEquals, Value: []byte("a"), Modifier: CaseSensitive}},
+		{name: "Escapes are kept", input: `[data-x='it\'s']`, expected: &AttributeSelector{Name: []byte("data-x"), Matcher: MatchEquals, Value: []byte(`it\'s`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, errors := Parse(lexer.Lex(strings.NewReader(tt.input + " { color: red; }")))
+			if len(errors) > 0 {
+				t.Fatalf("Unexpected errors: %v", errors)
+			}
+			selector := result.Rules[0].(*Selector)
+			got := selector.Selectors[0].Compounds[0].Selectors[0]
+			if got.String() != tt.expected.String() {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAttributeSelectorErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		error string
+	}{
+		{name: "Missing name", input: "[=x]", error: "Expected attribute name"},
+		{name: "Missing value", input: "[a=]", error: "Expected identifier or string as attribute value"},
+		{name: "Number value", input: "[a=1]", error: "Expected identifier or string as attribute value"},
+		{name: "Unknown matcher", input: "[a~b]", error: "Expected '=' in attribute matcher"},
+		{name: "Missing matcher", input: "[a b]", error: "Expected attribute matcher"},
+		{name: "Space inside the matcher", input: "[a| =b]", error: "Expected '=' in attribute matcher"},
+		{name: "Unknown modifier", input: "[a=b x]", error: "Unknown attribute selector modifier"},
+		{name: "Two values", input: "[a=b c d]", error: "Unknown attribute selector modifier"},
+		{name: "Unclosed", input: "[a=b i", error: "Expected closing bracket for attribute selector"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errors := Parse(lexer.Lex(strings.NewReader(tt.input + " { color: red; }")))
+			if len(errors) == 0 || errors[0].Message != tt.error {
+				t.Errorf("Expected the error %q, got %v", tt.error, errors)
+			}
+		})
+	}
+}
+
+func TestPseudoClassArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Stylesheet
+	}{
+		{
+			name:  "Selector lists",
+			input: ":is(h1, .title > span):where(#main) { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{
+									&PseudoSelector{Name: []byte("is"), Functional: true, Selectors: SelectorList{
+										{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("h1")}}}}},
+										{Compounds: []*CompoundSelector{
+											{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("title")}}},
+											{Combinator: Child, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("span")}}},
+										}},
+									}},
+									&PseudoSelector{Name: []byte("where"), Functional: true, Selectors: SelectorList{
+										{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&IDSelector{Name: []byte("main")}}}}},
+									}},
+								}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Relative selectors",
+			input: "a:has(> img, + p, span:not(.x)) { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{
+									&TypeSelector{Name: []byte("a")},
+									&PseudoSelector{Name: []byte("has"), Functional: true, Selectors: SelectorList{
+										{Compounds: []*CompoundSelector{{Combinator: Child, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("img")}}}}},
+										{Compounds: []*CompoundSelector{{Combinator: NextSibling, Selectors: []SimpleSelector{&TypeSelector{Name: []byte("p")}}}}},
+										{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{
+											&TypeSelector{Name: []byte("span")},
+											&PseudoSelector{Name: []byte("not"), Functional: true, Selectors: SelectorList{
+												{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("x")}}}}},
+											}},
+										}}}},
+									}},
+								}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Nth with a selector list and other functional pseudo-classes",
+			input: "li:nth-child(2n+1 of .item):lang(en)::part(label) { color: red; }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&Selector{
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{
+									&TypeSelector{Name: []byte("li")},
+									&PseudoSelector{Name: []byte("nth-child"), Functional: true, Nth: &AnPlusB{A: 2, B: 1, Of: SelectorList{
+										{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("item")}}}}},
+									}}},
+									&PseudoSelector{Name: []byte("lang"), Functional: true, Arguments: []byte("en")},
+									&PseudoSelector{Name: []byte("part"), Element: true, Functional: true, Arguments: []byte("label")},
+								}},
+							}},
+						},
+						Rules: []Node{
+							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
+
+func TestAnPlusB(t *testing.T) {
+	tests := []struct {
+		input string
+		a, b  int
+	}{
+		{"odd", 2, 1},
+		{"EVEN", 2, 0},
+		{"3", 0, 3},
+		{"+5", 0, 5},
+		{"-2", 0, -2},
+		{"n", 1, 0},
+		{"-n+3", -1, 3},
+		{"+n", 1, 0},
+		{"2n+1", 2, 1},
+		{"2n + 1", 2, 1},
+		{"2n-1", 2, -1},
+		{"-2n- 1", -2, -1},
+		{"10N-3", 10, -3},
+		{"0n", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, errors := Parse(lexer.Lex(strings.NewReader(":nth-of-type(" + tt.input + ") {}")))
+			if len(errors) > 0 {
+				t.Fatalf("Unexpected errors: %v", errors)
+			}
+			pseudo := result.Rules[0].(*Selector).Selectors[0].Compounds[0].Selectors[0].(*PseudoSelector)
+			if pseudo.Nth == nil || pseudo.Nth.A != tt.a || pseudo.Nth.B != tt.b {
+				t.Errorf("Expected A=%d B=%d, got %v", tt.a, tt.b, pseudo.Nth)
+			}
+		})
+	}
+}
+
+func TestPseudoClassArgumentErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Empty selector list", input: ":not() {}"},
+		{name: "Leading combinator outside :has", input: ":is(> a) {}"},
+		{name: "Invalid An+B", input: ":nth-child(2 n) {}"},
+		{name: "Sign without digits", input: ":nth-child(n+) {}"},
+		{name: "Two signs", input: ":nth-child(n+-1) {}"},
+		{name: "Selector list in nth-of-type", input: ":nth-of-type(2n of .a) {}"},
+		{name: "Unclosed", input: ":not(.a .b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errors := Parse(lexer.Lex(strings.NewReader(tt.input)))
+			if len(errors) == 0 {
+				t.Errorf("Expected an error for %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestSelectorErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Combinator without a selector after it", input: "a > { color: red; }"},
+		{name: "Leading combinator", input: "a, > b { color: red; }"},
+		{name: "Two combinators in a row", input: "a > + b { color: red; }"},
+		{name: "Empty selector in a list", input: "a, , b { color: red; }"},
+		{name: "Unexpected token", input: "a ; b { color: red; }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, errors := Parse(lexer.Lex(strings.NewReader(tt.input)))
+			if len(errors) == 0 {
+				t.Errorf("Expected an error for %q", tt.input)
+			}
+			if len(result.Rules) != 1 {
+				t.Fatalf("Expected the rule to be recovered, got %d rules", len(result.Rules))
+			}
+			if _, ok := result.Rules[0].(*Selector); !ok {
+				t.Errorf("Expected a selector, got %T", result.Rules[0])
+			}
+		})
+	}
+}
+
 func TestPseudoSelectors(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -199,9 +637,10 @@ func TestPseudoSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Element, Value: []byte("a")},
-							{Type: Pseudo, Value: []byte(":hover")},
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("a")}, &PseudoSelector{Name: []byte("hover")}}},
+							}},
 						},
 						Rules: []Node{
 							&Declaration{Key: []byte("color"), Value: []Value{&BasicValue{Value: []byte("red")}}},
@@ -216,9 +655,10 @@ func TestPseudoSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Element, Value: []byte("p")},
-							{Type: Pseudo, Value: []byte("::first-line")},
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("p")}, &PseudoSelector{Name: []byte("first-line"), Element: true}}},
+							}},
 						},
 						Rules: []Node{
 							&Declaration{Key: []byte("font-weight"), Value: []Value{&BasicValue{Value: []byte("bold")}}},
@@ -233,10 +673,10 @@ func TestPseudoSelectors(t *testing.T) {
 			expected: &Stylesheet{
 				Rules: []Node{
 					&Selector{
-						Selectors: []SelectorValue{
-							{Type: Element, Value: []byte("a")},
-							{Type: Pseudo, Value: []byte(":hover")},
-							{Type: Pseudo, Value: []byte("::before")},
+						Selectors: SelectorList{
+							{Compounds: []*CompoundSelector{
+								{Selectors: []SimpleSelector{&TypeSelector{Name: []byte("a")}, &PseudoSelector{Name: []byte("hover")}, &PseudoSelector{Name: []byte("before"), Element: true}}},
+							}},
 						},
 						Rules: []Node{
 							&Declaration{Key: []byte("content"), Value: []Value{&StringValue{SingleQuote: true, Value: []byte("→")}}},
@@ -353,3 +793,28 @@ func findNextMatch(expected, actual []string) matchOffset {
 	}
 	return matchOffset{len(expected), len(actual)}
 }
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		errors   int
+	}{
+		{input: "ul > li.item, a:not(.b)", expected: `[[{Selectors: [Type("ul")]} {Combinator: ">", Selectors: [Type("li"), Class("item")]}], [{Selectors: [Type("a"), Pseudo(":not", [[{Selectors: [Class("b")]}]])]}]]`},
+		{input: "/* comment */ #main", expected: `[[{Selectors: [ID("main")]}]]`},
+		{input: "a { color: red; }", expected: `[[{Selectors: [Type("a")]}]]`, errors: 1},
+		{input: "a >", expected: `[[{Selectors: [Type("a")]}]]`, errors: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			list, errors := ParseSelector(lexer.Lex(strings.NewReader(tt.input)))
+			if len(errors) != tt.errors {
+				t.Errorf("Expected %d errors, got %v", tt.errors, errors)
+			}
+			if got := list.inline(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// legacyPseudoElements are the pseudo-elements that can also be written with a single colon.
+var legacyPseudoElements = map[string]bool{
+	"before": true, "after": true, "first-line": true, "first-letter": true,
+}
+
+// Specificity returns the specificity of a complex selector as defined by Selectors Level 4.
+//
+// Parameters:
+// - sel: The selector. Its combinators do not affect its specificity.
+//
+// Returns:
+// - a: The number of id selectors.
+// - b: The number of class selectors, attribute selectors and pseudo-classes.
+// - c: The number of type selectors and pseudo-elements.
+//
+// :is(), :not() and :has() count as their most specific argument, and :where() counts as
+// nothing. :nth-child() and :nth-last-child() count as a pseudo-class plus their most specific
+// "of" selector. The universal selector and namespace prefixes count as nothing, as does the
+// nesting selector, which depends on the enclosing rule. SpecificityReport resolves it.
+func Specificity(sel *ComplexSelector) (a, b, c int) {
+	s := complexSpecificity(sel, specificity{})
+	return s[0], s[1], s[2]
+}
+
+// specificity holds the a, b and c components of a selector's specificity.
+type specificity [3]int
+
+func (s specificity) add(other specificity) specificity {
+	return specificity{s[0] + other[0], s[1] + other[1], s[2] + other[2]}
+}
+
+// less reports whether s is less specific than other, comparing the components in order.
+func (s specificity) less(other specificity) bool {
+	for i := range s {
+		if s[i] != other[i] {
+			return s[i] < other[i]
+		}
+	}
+	return false
+}
+
+// complexSpecificity returns the specificity of a selector whose nesting selectors count as
+// nesting.
+func complexSpecificity(sel *ComplexSelector, nesting specificity) specificity {
+	var s specificity
+	for _, compound := range sel.Compounds {
+		for _, simple := range compound.Selectors {
+			s = s.add(simpleSpecificity(simple, nesting))
+		}
+	}
+	return s
+}
+
+// maxSpecificity returns the specificity of the most specific selector in the list.
+func maxSpecificity(list SelectorList, nesting specificity) specificity {
+	var most specificity
+	for _, sel := range list {
+		if s := complexSpecificity(sel, nesting); most.less(s) {
+			most = s
+		}
+	}
+	return most
+}
+
+func simpleSpecificity(simple SimpleSelector, nesting specificity) specificity {
+	switch s := simple.(type) {
+	case *NestingSelector:
+		return nesting
+	case *IDSelector:
+		return specificity{1, 0, 0}
+	case *ClassSelector, *AttributeSelector:
+		return specificity{0, 1, 0}
+	case *TypeSelector:
+		return specificity{0, 0, 1}
+	case *PseudoSelector:
+		return pseudoSpecificity(s, nesting)
+	}
+	return specificity{}
+}
+
+func pseudoSpecificity(pseudo *PseudoSelector, nesting specificity) specificity {
+	name := strings.ToLower(string(pseudo.Name))
+	if pseudo.Element || legacyPseudoElements[name] {
+		return specificity{0, 0, 1}
+	}
+
+	switch name {
+	case "where":
+		return specificity{}
+	case "is", "not", "has":
+		return maxSpecificity(pseudo.Selectors, nesting)
+	case "nth-child", "nth-last-child":
+		s := specificity{0, 1, 0}
+		if pseudo.Nth != nil {
+			s = s.add(maxSpecificity(pseudo.Nth.Of, nesting))
+		}
+		return s
+	}
+	return specificity{0, 1, 0}
+}
+
+// SelectorSpecificity is a selector of a style rule with its specificity.
+type SelectorSpecificity struct {
+	Selector *ComplexSelector
+	// Position is the position of the style rule that the selector belongs to
+	Position Position
+	A, B, C  int
+}
+
+// FileSpecificity lists the most specific selectors of a file.
+type FileSpecificity struct {
+	// Source is the file the selectors were parsed from, as recorded in their Position
+	Source    string
+	Selectors []SelectorSpecificity
+}
+
+// SpecificityReport lists the most specific selectors of each file in the stylesheet, including
+// those of style rules within @media, @supports, @layer and @container blocks. Each selector of
+// a selector list is listed on its own. The specificity of a nested style rule includes the most
+// specific selector of the enclosing rule, once for each nesting selector or once if it has none.
+//
+// Parameters:
+// - s: The stylesheet, such as a bundle or the result of parsing a file with WithSource.
+// - limit: The maximum number of selectors listed per file. All selectors are listed when it is
+// zero or less.
+//
+// Returns:
+// - The files sorted by source. The selectors of each file are sorted from the most specific,
+// with selectors of equal specificity in the order they appear.
+func SpecificityReport(s *Stylesheet, limit int) []FileSpecificity {
+	bySource := make(map[string][]SelectorSpecificity)
+	collectSpecificity(s.Rules, nil, bySource)
+
+	report := make([]FileSpecificity, 0, len(bySource))
+	for source, selectors := range bySource {
+		sort.SliceStable(selectors, func(i, j int) bool {
+			return specificityOf(selectors[j]).less(specificityOf(selectors[i]))
+		})
+		if limit > 0 && len(selectors) > limit {
+			selectors = selectors[:limit]
+		}
+		report = append(report, FileSpecificity{Source: source, Selectors: selectors})
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Source < report[j].Source
+	})
+	return report
+}
+
+func specificityOf(s SelectorSpecificity) specificity {
+	return specificity{s.A, s.B, s.C}
+}
+
+// collectSpecificity adds the selectors of the style rules to bySource. nesting is the
+// specificity that a nesting selector stands for, or nil outside of a style rule.
+func collectSpecificity(rules []Node, nesting *specificity, bySource map[string][]SelectorSpecificity) {
+	for _, rule := range rules {
+		switch r := rule.(type) {
+		case *Selector:
+			// The nesting selector of the rules nested in this one stands for its most specific selector
+			var most specificity
+			for _, sel := range r.Selectors {
+				s := nestedSpecificity(sel, nesting)
+				if most.less(s) {
+					most = s
+				}
+				bySource[r.Source] = append(bySource[r.Source], SelectorSpecificity{
+					Selector: sel,
+					Position: r.Position,
+					A:        s[0],
+					B:        s[1],
+					C:        s[2],
+				})
+			}
+			collectSpecificity(r.Rules, &most, bySource)
+		case *MediaAtRule:
+			collectSpecificity(r.Rules, nesting, bySource)
+		case *SupportsAtRule:
+			collectSpecificity(r.Rules, nesting, bySource)
+		case *LayerAtRule:
+			collectSpecificity(r.Rules, nesting, bySource)
+		case *ContainerAtRule:
+			collectSpecificity(r.Declarations, nesting, bySource)
+		}
+	}
+}
+
+// nestedSpecificity returns the specificity of a selector within a style rule whose nesting
+// selector stands for nesting. A nested selector without a nesting selector is relative to the
+// enclosing rule, as if it started with "& ".
+func nestedSpecificity(sel *ComplexSelector, nesting *specificity) specificity {
+	if nesting == nil {
+		return complexSpecificity(sel, specificity{})
+	}
+	s := complexSpecificity(sel, *nesting)
+	if !containsNesting(SelectorList{sel}) {
+		s = s.add(*nesting)
+	}
+	return s
+}
+
+// containsNesting reports whether any selector of the list uses the nesting selector, including
+// within the arguments of pseudo-classes.
+func containsNesting(list SelectorList) bool {
+	for _, sel := range list {
+		for _, compound := range sel.Compounds {
+			for _, simple := range compound.Selectors {
+				switch s := simple.(type) {
+				case *NestingSelector:
+					return true
+				case *PseudoSelector:
+					if containsNesting(s.Selectors) || (s.Nth != nil && containsNesting(s.Nth.Of)) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
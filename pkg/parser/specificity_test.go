@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+)
+
+func TestSpecificity(t *testing.T) {
+	tests := []struct {
+		selector string
+		expected [3]int
+	}{
+		{"*", [3]int{0, 0, 0}},
+		{"li", [3]int{0, 0, 1}},
+		{"ul li", [3]int{0, 0, 2}},
+		{"ul > li + a", [3]int{0, 0, 3}},
+		{".nav", [3]int{0, 1, 0}},
+		{"a[href]:hover", [3]int{0, 2, 1}},
+		{"#main .item", [3]int{1, 1, 0}},
+		{"#a#b", [3]int{2, 0, 0}},
+		{"a|b.c", [3]int{0, 1, 1}},
+		{"a|*.c", [3]int{0, 1, 0}},
+		{"p::first-line", [3]int{0, 0, 2}},
+		{"p:before", [3]int{0, 0, 2}},
+		{":is(#a, .b) span", [3]int{1, 0, 1}},
+		{":not(.a, p .b)", [3]int{0, 1, 1}},
+		{"a:has(> img#logo)", [3]int{1, 0, 2}},
+		{":where(#a, .b) span", [3]int{0, 0, 1}},
+		{"li:nth-child(2n+1)", [3]int{0, 1, 1}},
+		{"li:nth-child(odd of #x, .y)", [3]int{1, 1, 1}},
+		{"li:nth-of-type(2)", [3]int{0, 1, 1}},
+		{":is(:not(#a), :where(#b))", [3]int{1, 0, 0}},
+		{"& .a", [3]int{0, 1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			input := "@namespace a 'x'; " + tt.selector + " {}"
+			result, errors := Parse(lexer.Lex(strings.NewReader(input)))
+			if len(errors) > 0 {
+				t.Fatalf("Unexpected errors: %v", errors)
+			}
+			a, b, c := Specificity(result.Rules[1].(*Selector).Selectors[0])
+			if got := [3]int{a, b, c}; got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSpecificityReport(t *testing.T) {
+	parse := func(source, input string) []Node {
+		result, errors := Parse(lexer.Lex(strings.NewReader(input)), WithSource(source))
+		if len(errors) > 0 {
+			t.Fatalf("Unexpected errors: %v", errors)
+		}
+		return result.Rules
+	}
+
+	stylesheet := NewStylesheet()
+	stylesheet.Rules = append(stylesheet.Rules, parse("b.css", `
+		.a { color: red; }
+		@media print { #b .c, p { color: red; } }
+		.d.e { color: red; }
+	`)...)
+	stylesheet.Rules = append(stylesheet.Rules, parse("a.css", `
+		@supports (display: grid) { @layer base { ul li { color: red; } } }
+		div { color: red; }
+	`)...)
+
+	report := SpecificityReport(stylesheet, 2)
+	if len(report) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(report))
+	}
+
+	expected := []struct {
+		source    string
+		selectors []string
+		lines     []int
+	}{
+		{source: "a.css", selectors: []string{"(0, 0, 2)", "(0, 0, 1)"}, lines: []int{2, 3}},
+		{source: "b.css", selectors: []string{"(1, 1, 0)", "(0, 2, 0)"}, lines: []int{3, 4}},
+	}
+	for i, file := range report {
+		if file.Source != expected[i].source {
+			t.Errorf("File %d: expected %s, got %s", i, expected[i].source, file.Source)
+		}
+		if len(file.Selectors) != len(expected[i].selectors) {
+			t.Fatalf("%s: expected %d selectors, got %d", file.Source, len(expected[i].selectors), len(file.Selectors))
+		}
+		for j, sel := range file.Selectors {
+			if got := fmt.Sprintf("(%d, %d, %d)", sel.A, sel.B, sel.C); got != expected[i].selectors[j] {
+				t.Errorf("%s selector %d: expected %s, got %s", file.Source, j, expected[i].selectors[j], got)
+			}
+			if sel.Position.Line != expected[i].lines[j] || sel.Position.Source != file.Source {
+				t.Errorf("%s selector %d: expected line %d, got %s:%d", file.Source, j, expected[i].lines[j], sel.Position.Source, sel.Position.Line)
+			}
+		}
+	}
+}
+
+func TestNestedSpecificityReport(t *testing.T) {
+	input := `#app {
+		.a { color: red; &:hover .b { color: red; } }
+		@media print { > p, .c & { color: red; } }
+	}`
+	stylesheet, errors := Parse(lexer.Lex(strings.NewReader(input)))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	report := SpecificityReport(stylesheet, 0)
+	if len(report) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(report))
+	}
+
+	expected := []string{"(1, 3, 0)", "(1, 1, 0)", "(1, 1, 0)", "(1, 0, 1)", "(1, 0, 0)"}
+	var got []string
+	for _, sel := range report[0].Selectors {
+		got = append(got, fmt.Sprintf("(%d, %d, %d)", sel.A, sel.B, sel.C))
+	}
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
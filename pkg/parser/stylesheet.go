@@ -6,7 +6,6 @@ import (
 	"github.com/aledsdavies/pristinecss/pkg/tokens"
 )
 
-
 const (
 	NodeStylesheet NodeType = "stylesheet"
 )
@@ -18,6 +17,8 @@ func init() {
 var _ Node = (*Stylesheet)(nil)
 
 type Stylesheet struct {
+	Position
+
 	Rules []Node
 }
 
@@ -25,6 +26,19 @@ func NewStylesheet() *Stylesheet {
 	return &Stylesheet{Rules: []Node{}}
 }
 
+// Namespaces returns the namespace URIs declared by the stylesheet's @namespace rules, keyed by
+// prefix. The default namespace has the empty prefix. A later declaration of a prefix replaces
+// an earlier one.
+func (s *Stylesheet) Namespaces() map[string]string {
+	namespaces := make(map[string]string)
+	for _, rule := range s.Rules {
+		if ns, ok := rule.(*NamespaceAtRule); ok {
+			namespaces[string(ns.Prefix)] = ns.URI()
+		}
+	}
+	return namespaces
+}
+
 func (s *Stylesheet) Type() NodeType { return NodeStylesheet }
 func (s *Stylesheet) String() string {
 	var sb strings.Builder
@@ -43,15 +57,19 @@ func (s *Stylesheet) String() string {
 
 func visitStylesheet(pv *ParseVisitor, node Node) {
 	s := node.(*Stylesheet)
+	// The stylesheet spans the whole source, including any whitespace around its rules
+	s.Position = Position{Source: pv.source, Line: 1, Column: 1}
+	defer func() { s.setEnd(pv.currentToken.End) }()
 	for !pv.currentTokenIs(tokens.EOF) {
 		var childNode Node
 		switch pv.currentToken.Type {
 		case tokens.COMMENT:
 			childNode = &Comment{Text: pv.currentToken.Literal}
 			visitComment(pv, childNode)
-		case tokens.DOT, tokens.HASH, tokens.COLON, tokens.DBLCOLON, tokens.IDENT, tokens.LBRACKET:
+		case tokens.DOT, tokens.HASH, tokens.COLOR, tokens.COLON, tokens.DBLCOLON, tokens.IDENT, tokens.LBRACKET,
+			tokens.ASTERISK, tokens.PIPE, tokens.AMPERSAND:
 			childNode = &Selector{
-				Selectors: make([]SelectorValue, 0),
+				Selectors: SelectorList{},
 				Rules:     make([]Node, 0),
 			}
 			visitSelector(pv, childNode)
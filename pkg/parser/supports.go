@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+const (
+	Supports AtType = "supports"
+)
+
+func init() {
+	RegisterAt(Supports, visitSupportsAtRule, func() AtRule { return &SupportsAtRule{} })
+}
+
+var _ Node = (*SupportsAtRule)(nil)
+
+type SupportsAtRule struct {
+	Position
+
+	Condition SupportsCondition
+	Rules     []Node
+}
+
+func (s *SupportsAtRule) Type() NodeType { return NodeAtRule }
+func (s *SupportsAtRule) AtType() AtType { return Supports }
+func (s *SupportsAtRule) String() string {
+	var sb strings.Builder
+	sb.WriteString("SupportsAtRule{\n")
+	if s.Condition != nil {
+		sb.WriteString("  Condition: ")
+		sb.WriteString(strings.TrimLeft(supportConditionToString(s.Condition, 1), " "))
+		sb.WriteString(",\n")
+	}
+	if len(s.Rules) > 0 {
+		sb.WriteString("  Rules: [\n")
+		for _, rule := range s.Rules {
+			sb.WriteString(indentLines(rule.String(), 4))
+			sb.WriteString(",\n")
+		}
+		sb.WriteString("  ]\n")
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func visitSupportsAtRule(pv *ParseVisitor, node AtRule) {
+	s := node.(*SupportsAtRule)
+	pv.advance() // Consume 'supports'
+
+	// A condition joined by and/or at the top level is held as a group, as it is in parentheses
+	group := &SupportsGroup{Position: pv.pos()}
+	for !pv.currentTokenIs(tokens.LBRACE) && !pv.currentTokenIs(tokens.EOF) {
+		switch {
+		case pv.currentTokenIs(tokens.IDENT) && string(pv.currentToken.Literal) == "not":
+			not := &SupportsNot{Position: pv.pos()}
+			pv.advance() // Consume 'not'
+			not.Condition = pv.parseSupportsCondition()
+			if not.Condition == nil {
+				return
+			}
+			pv.endPos(&not.Position)
+			group.Conditions = append(group.Conditions, not)
+		case pv.currentTokenIs(tokens.IDENT) && (string(pv.currentToken.Literal) == "and" || string(pv.currentToken.Literal) == "or"):
+			operator := &SupportsOperator{Position: pv.pos(), Operator: string(pv.currentToken.Literal)}
+			pv.advance()
+			pv.endPos(&operator.Position)
+			group.Conditions = append(group.Conditions, operator)
+		case pv.currentTokenIs(tokens.IDENT) && pv.nextTokenIs(tokens.LPAREN):
+			group.Conditions = append(group.Conditions, pv.parseSupportsFunction())
+		default:
+			condition := pv.parseSupportsCondition()
+			if condition == nil {
+				return
+			}
+			group.Conditions = append(group.Conditions, condition)
+		}
+	}
+
+	pv.endPos(&group.Position)
+	if len(group.Conditions) == 1 {
+		s.Condition = group.Conditions[0]
+	} else {
+		s.Condition = group
+	}
+
+	if !pv.consume(tokens.LBRACE, "Expected '{' after supports condition") {
+		return
+	}
+	s.Rules = pv.parseRuleBlock("supports")
+}
@@ -0,0 +1,49 @@
+package parser
+
+import "testing"
+
+func TestSupportsAtRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Stylesheet
+	}{
+		{
+			name:  "Declaration condition",
+			input: "@supports (display: grid) { .a { display: grid; } }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&SupportsAtRule{
+						Condition: &SupportsDecleration{Key: []byte("display"), Value: []Value{&BasicValue{Value: []byte("grid")}}},
+						Rules: []Node{
+							&Selector{
+								Selectors: SelectorList{{Compounds: []*CompoundSelector{{Selectors: []SimpleSelector{&ClassSelector{Name: []byte("a")}}}}}},
+								Rules: []Node{
+									&Declaration{Key: []byte("display"), Value: []Value{&BasicValue{Value: []byte("grid")}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Combined conditions",
+			input: "@supports (not (display: grid)) and selector(:has(a)) { }",
+			expected: &Stylesheet{
+				Rules: []Node{
+					&SupportsAtRule{
+						Condition: &SupportsGroup{
+							Conditions: []SupportsCondition{
+								&SupportsNot{Condition: &SupportsDecleration{Key: []byte("display"), Value: []Value{&BasicValue{Value: []byte("grid")}}}},
+								&SupportsOperator{Operator: "and"},
+								&SupportsFunction{Name: []byte("selector"), Args: []byte(":has(a)")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	runTests(t, tests)
+}
@@ -1,12 +1,11 @@
 package parser
 
 import (
-	"bytes"
 	"fmt"
 	"strings"
 )
 
-func selectorTypeToString(st SelectorType) string {
+func (st SelectorType) String() string {
 	switch st {
 	case Element:
 		return "Element"
@@ -18,13 +17,33 @@ func selectorTypeToString(st SelectorType) string {
 		return "Attribute"
 	case Pseudo:
 		return "Pseudo"
-	case Combinator:
-		return "Combinator"
+	case Universal:
+		return "Universal"
+	case Nesting:
+		return "Nesting"
 	default:
 		return fmt.Sprintf("Unknown(%d)", st)
 	}
 }
 
+// unquote returns the content of a string token without its quotes. The closing quote is missing
+// from a string left open at the end of the input.
+func unquote(literal []byte) []byte {
+	content := literal[1:]
+	if len(content) > 0 && content[len(content)-1] == literal[0] {
+		content = content[:len(content)-1]
+	}
+	return content
+}
+
+// concat returns a new slice holding a followed by b. Literals are slices of the lexer's input,
+// so appending to one directly would overwrite the bytes that follow it in the input.
+func concat(a, b []byte) []byte {
+	joined := make([]byte, 0, len(a)+len(b))
+	joined = append(joined, a...)
+	return append(joined, b...)
+}
+
 func indentLines(s string, spaces int) string {
 	lines := strings.Split(s, "\n")
 	for i, line := range lines {
@@ -34,46 +53,3 @@ func indentLines(s string, spaces int) string {
 	}
 	return strings.Join(lines, "\n")
 }
-
-var units = [][]byte{
-	// Absolute length units
-	[]byte("cm"), []byte("mm"), []byte("in"), []byte("px"), []byte("pt"), []byte("pc"), []byte("Q"),
-
-	// Relative length units
-	[]byte("em"), []byte("ex"), []byte("ch"), []byte("rem"), []byte("lh"), []byte("rlh"), []byte("vb"), []byte("vi"),
-
-	// Viewport-percentage lengths
-	[]byte("vw"), []byte("vh"), []byte("vmin"), []byte("vmax"),
-	[]byte("svw"), []byte("svh"), []byte("lvw"), []byte("lvh"),
-	[]byte("dvw"), []byte("dvh"), []byte("vi"), []byte("vb"),
-
-	// Container query length units
-	[]byte("cqw"), []byte("cqh"), []byte("cqi"), []byte("cqb"), []byte("cqmin"), []byte("cqmax"),
-
-	// Percentage (handled separately in most cases, but included for completeness)
-	[]byte("%"),
-
-	// Angle units
-	[]byte("deg"), []byte("grad"), []byte("rad"), []byte("turn"),
-
-	// Time units
-	[]byte("s"), []byte("ms"),
-
-	// Frequency units
-	[]byte("Hz"), []byte("kHz"),
-
-	// Resolution units
-	[]byte("dpi"), []byte("dpcm"), []byte("dppx"),
-
-	// Flex units
-	[]byte("fr"),
-}
-
-func isUnit(literal []byte) bool {
-	for _, unit := range units {
-		if bytes.Equal(literal, unit) {
-			return true
-		}
-	}
-	return false
-}
@@ -29,15 +29,16 @@ func init() {
 type ValueType int
 
 const (
-    Basic ValueType = iota
-    String
-    Function
-    ValueComment
+	Basic ValueType = iota
+	String
+	Function
+	ValueComment
 )
 
 type Value interface {
-    Node
-    ValueType() ValueType
+	Node
+	Positioned
+	ValueType() ValueType
 }
 
 var _ Value = (*BasicValue)(nil)
@@ -45,6 +46,8 @@ var _ Value = (*StringValue)(nil)
 var _ Value = (*FunctionValue)(nil)
 
 type BasicValue struct {
+	Position
+
 	Value []byte
 }
 
@@ -55,6 +58,8 @@ func (bv *BasicValue) String() string {
 }
 
 type StringValue struct {
+	Position
+
 	SingleQuote bool
 	Value       []byte
 }
@@ -68,6 +73,8 @@ func (bv *StringValue) String() string {
 var _ Value = (*FunctionValue)(nil)
 
 type FunctionValue struct {
+	Position
+
 	Name      []byte
 	Arguments []Value
 }
@@ -89,29 +96,33 @@ func (fv *FunctionValue) String() string {
 
 func visitBasicValue(pv *ParseVisitor, node Node) {
 	bv := node.(*BasicValue)
+	bv.Position = pv.pos()
 	bv.Value = pv.currentToken.Literal
 	pv.advance()
+	pv.endPos(&bv.Position)
 }
 
 func visitStringValue(pv *ParseVisitor, node Node) {
 	sv := node.(*StringValue)
+	sv.Position = pv.pos()
 	str := pv.currentToken.Literal
 	sv.SingleQuote = str[0] == '\''
 	sv.Value = str[1 : len(str)-1] // Remove the quotes
 	pv.advance()
+	pv.endPos(&sv.Position)
 }
 
 func visitFunctionValue(pv *ParseVisitor, node Node) {
 	fv := node.(*FunctionValue)
+	fv.Position = pv.pos()
+	defer pv.endPos(&fv.Position)
 	fv.Name = pv.currentToken.Literal
 	pv.advance() // Move to '('
 	pv.advance() // Move past '('
 
 	for !pv.currentTokenIs(tokens.RPAREN) && !pv.currentTokenIs(tokens.EOF) {
+		// Commas are parsed as values so the arguments can be printed back
 		fv.Arguments = append(fv.Arguments, pv.parseValue())
-		if pv.currentTokenIs(tokens.COMMA) {
-			pv.advance()
-		}
 	}
 
 	pv.consume(tokens.RPAREN, "Expected ')' to close function")
@@ -144,27 +155,41 @@ func (pv *ParseVisitor) parseValue() Value {
 	return value
 }
 
+// parseNumberValue parses a number and its unit. Any identifier directly after the number is its
+// unit, whatever its case, so that dimensions such as 2x and 0PX are never split in two. An
+// identifier after whitespace is a separate value, as in "900 CM".
 func (pv *ParseVisitor) parseNumberValue() Value {
-	number := pv.currentToken.Literal
+	value := &BasicValue{Position: pv.pos(), Value: pv.currentToken.Literal}
 	pv.advance()
-	if pv.currentTokenIs(tokens.PERCENTAGE) || isUnit(pv.currentToken.Literal) {
-		number = append(number, pv.currentToken.Literal...)
+	adjacentIdent := pv.currentTokenIs(tokens.IDENT) && !pv.currentToken.SpaceBefore
+	if pv.currentTokenIs(tokens.PERCENTAGE) || adjacentIdent {
+		value.Value = concat(value.Value, pv.currentToken.Literal)
 		pv.advance()
 	}
-	return &BasicValue{Value: number}
+	pv.endPos(&value.Position)
+	return value
 }
 
 func (pv *ParseVisitor) parseURLValue() Value {
 	urlContent, singleQuote, quoteless := extractURLContent(pv.currentToken.Literal)
+	pos := pv.pos()
+	pos.End = pv.currentToken.End
+
+	// The argument spans the token between "url(" and ")", including any quotes
+	argPos := pos
+	argPos.Column += 4
+	argPos.Offset += 4
+	argPos.End--
 
 	var arg Value
 	if quoteless {
-		arg = &BasicValue{Value: urlContent}
+		arg = &BasicValue{Position: argPos, Value: urlContent}
 	} else {
-		arg = &StringValue{SingleQuote: singleQuote, Value: urlContent}
+		arg = &StringValue{Position: argPos, SingleQuote: singleQuote, Value: urlContent}
 	}
 
 	return &FunctionValue{
+		Position:  pos,
 		Name:      []byte("url"),
 		Arguments: []Value{arg},
 	}
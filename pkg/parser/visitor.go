@@ -14,6 +14,8 @@ type AtVisitFunc func(*ParseVisitor, AtRule)
 
 var nodeRegistry = make(map[NodeType]VisitFunc)
 
+// RegisterNodeType registers the visitor of a node type. Registration is not synchronised with
+// parsing, so it must only happen in an init function.
 func RegisterNodeType(nodeType NodeType, visitFunc VisitFunc) {
 	nodeRegistry[nodeType] = visitFunc
 }
@@ -32,6 +34,8 @@ type AtType string
 var atRegistry = make(map[AtType]AtVisitFunc)
 var keywordRegistry = make(map[string]AtInit)
 
+// RegisterAt registers the visitor of an at-rule and the function that creates its node.
+// Registration is not synchronised with parsing, so it must only happen in an init function.
 func RegisterAt(atType AtType, visitFunc AtVisitFunc, initFunc AtInit) {
 	atRegistry[atType] = visitFunc
 	keywordRegistry[string(atType)] = initFunc
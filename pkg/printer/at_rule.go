@@ -0,0 +1,310 @@
+package printer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+)
+
+func (p *printer) printAtRule(rule parser.AtRule) error {
+	switch r := rule.(type) {
+	case *parser.CharsetAtRule:
+		p.buf.WriteString("@charset ")
+		if err := p.printValue(r.Charset); err != nil {
+			return err
+		}
+		p.buf.WriteByte(';')
+	case *parser.ImportAtRule:
+		return p.printImport(r)
+	case *parser.NamespaceAtRule:
+		p.buf.WriteString("@namespace ")
+		if r.Prefix != nil {
+			p.buf.Write(r.Prefix)
+			p.buf.WriteByte(' ')
+		}
+		if err := p.printValue(r.URL); err != nil {
+			return err
+		}
+		p.buf.WriteByte(';')
+	case *parser.MediaAtRule:
+		p.buf.WriteString("@media ")
+		p.printMediaQuery(r.Query)
+		return p.printBlock(r.Rules)
+	case *parser.KeyframesAtRule:
+		return p.printKeyframes(r)
+	case *parser.FontFaceAtRule:
+		p.buf.WriteString("@font-face")
+		return p.printDeclarationBlock(r.Declarations)
+	case *parser.ContainerAtRule:
+		p.buf.WriteString("@container")
+		if r.Name != nil {
+			p.buf.WriteByte(' ')
+			p.buf.Write(r.Name)
+		}
+		p.printContainerQuery(r.Query)
+		return p.printBlock(r.Declarations)
+	case *parser.CounterStyleAtRule:
+		p.buf.WriteString("@counter-style ")
+		p.buf.Write(r.Name)
+		return p.printDeclarationBlock(r.Declarations)
+	case *parser.ColorProfileAtRule:
+		p.buf.WriteString("@color-profile ")
+		if r.IsDeviceCMYK {
+			p.buf.WriteString("device-cmyk")
+		} else {
+			p.buf.Write(r.Name)
+		}
+		return p.printDeclarationBlock(r.Declarations)
+	case *parser.FontFeatureValuesAtRule:
+		return p.printFontFeatureValues(r)
+	case *parser.LayerAtRule:
+		p.buf.WriteString("@layer")
+		for i, name := range r.Names {
+			if i > 0 {
+				p.buf.WriteByte(',')
+				p.space()
+			} else {
+				p.buf.WriteByte(' ')
+			}
+			p.buf.Write(name)
+		}
+		if r.IsStatement {
+			p.buf.WriteByte(';')
+			return nil
+		}
+		return p.printBlock(r.Rules)
+	case *parser.SupportsAtRule:
+		p.buf.WriteString("@supports ")
+		if err := p.printSupportsPrelude(r.Condition); err != nil {
+			return err
+		}
+		return p.printBlock(r.Rules)
+	default:
+		return fmt.Errorf("printer: unsupported at-rule type %T", rule)
+	}
+	return nil
+}
+
+// printDeclarationBlock prints a block of declarations held by value.
+func (p *printer) printDeclarationBlock(declarations []parser.Declaration) error {
+	rules := make([]parser.Node, len(declarations))
+	for i := range declarations {
+		rules[i] = &declarations[i]
+	}
+	return p.printBlock(rules)
+}
+
+func (p *printer) printImport(r *parser.ImportAtRule) error {
+	p.buf.WriteString("@import ")
+	if err := p.printValue(r.URL); err != nil {
+		return err
+	}
+	if r.Layer != nil {
+		p.buf.WriteByte(' ')
+		if err := p.printValue(r.Layer); err != nil {
+			return err
+		}
+	}
+	if r.Supports != nil {
+		p.buf.WriteString(" supports(")
+		if err := p.printSupportsCondition(r.Supports); err != nil {
+			return err
+		}
+		p.buf.WriteByte(')')
+	}
+	if len(r.Media.Queries) > 0 {
+		p.buf.WriteByte(' ')
+		p.printMediaQuery(r.Media)
+	}
+	p.buf.WriteByte(';')
+	return nil
+}
+
+// printSupportsPrelude prints the condition of a @supports block, where a single declaration
+// needs its parentheses.
+func (p *printer) printSupportsPrelude(condition parser.SupportsCondition) error {
+	if _, ok := condition.(*parser.SupportsDecleration); ok {
+		p.buf.WriteByte('(')
+		if err := p.printSupportsCondition(condition); err != nil {
+			return err
+		}
+		p.buf.WriteByte(')')
+		return nil
+	}
+	return p.printSupportsCondition(condition)
+}
+
+// printSupportsCondition prints a condition without its enclosing parentheses.
+func (p *printer) printSupportsCondition(condition parser.SupportsCondition) error {
+	p.addMapping(condition)
+	switch c := condition.(type) {
+	case *parser.SupportsDecleration:
+		p.buf.Write(c.Key)
+		p.buf.WriteByte(':')
+		p.space()
+		return p.printValues(c.Value)
+	case *parser.SupportsFunction:
+		p.buf.Write(c.Name)
+		p.buf.WriteByte('(')
+		p.buf.Write(c.Args)
+		p.buf.WriteByte(')')
+	case *parser.SupportsOperator:
+		p.buf.WriteString(c.Operator)
+	case *parser.SupportsNot:
+		p.buf.WriteString("not (")
+		if err := p.printSupportsCondition(c.Condition); err != nil {
+			return err
+		}
+		p.buf.WriteByte(')')
+	case *parser.SupportsGroup:
+		for i, cond := range c.Conditions {
+			if i > 0 {
+				p.buf.WriteByte(' ')
+			}
+			if _, ok := cond.(*parser.SupportsOperator); ok {
+				if err := p.printSupportsCondition(cond); err != nil {
+					return err
+				}
+				continue
+			}
+			p.buf.WriteByte('(')
+			if err := p.printSupportsCondition(cond); err != nil {
+				return err
+			}
+			p.buf.WriteByte(')')
+		}
+	default:
+		return fmt.Errorf("printer: unsupported supports condition type %T", condition)
+	}
+	return nil
+}
+
+func (p *printer) printMediaQuery(query parser.MediaQuery) {
+	p.addMapping(query)
+	for i, expr := range query.Queries {
+		if i > 0 {
+			p.buf.WriteByte(',')
+			p.space()
+		}
+		p.printMediaQueryExpression(expr)
+	}
+}
+
+func (p *printer) printMediaQueryExpression(expr parser.MediaQueryExpression) {
+	p.addMapping(expr)
+	needsAnd := false
+	if expr.Not {
+		p.buf.WriteString("not ")
+	}
+	if expr.Only {
+		p.buf.WriteString("only ")
+	}
+	if expr.MediaType != nil {
+		p.buf.Write(expr.MediaType)
+		needsAnd = true
+	}
+	for _, feature := range expr.Features {
+		if needsAnd {
+			p.buf.WriteString(" and ")
+		}
+		p.addMapping(feature)
+		p.buf.WriteByte('(')
+		p.buf.Write(feature.Name)
+		if feature.Value != nil {
+			p.buf.WriteByte(':')
+			p.space()
+			p.buf.Write(feature.Value)
+		}
+		p.buf.WriteByte(')')
+		needsAnd = true
+	}
+}
+
+func (p *printer) printContainerQuery(query parser.ContainerQuery) {
+	for i, cond := range query.Conditions {
+		if i > 0 {
+			p.buf.WriteString(" and")
+		}
+		p.buf.WriteByte(' ')
+		if i == 0 {
+			p.addMapping(query)
+		}
+		p.addMapping(cond)
+		p.buf.WriteByte('(')
+		for j, feature := range cond.Features {
+			if j > 0 {
+				p.buf.WriteString(" and ")
+			}
+			p.addMapping(feature)
+			p.buf.Write(feature.Name)
+			p.buf.WriteByte(':')
+			p.space()
+			p.buf.Write(feature.Value)
+		}
+		p.buf.WriteByte(')')
+	}
+}
+
+func (p *printer) printKeyframes(k *parser.KeyframesAtRule) error {
+	if k.WebKitPrefix {
+		p.buf.WriteString("@-webkit-keyframes ")
+	} else {
+		p.buf.WriteString("@keyframes ")
+	}
+	p.buf.Write(k.Name)
+	p.space()
+	p.buf.WriteByte('{')
+	p.depth++
+	for _, stop := range k.Stops {
+		p.newline()
+		p.addMapping(stop)
+		for i, value := range stop.Stops {
+			if i > 0 {
+				p.buf.WriteByte(',')
+				p.space()
+			}
+			if err := p.printValue(value); err != nil {
+				return err
+			}
+		}
+		if err := p.printBlock(stop.Rules); err != nil {
+			return err
+		}
+	}
+	p.depth--
+	if len(k.Stops) > 0 {
+		p.newline()
+	}
+	p.buf.WriteByte('}')
+	return nil
+}
+
+func (p *printer) printFontFeatureValues(r *parser.FontFeatureValuesAtRule) error {
+	p.buf.WriteString("@font-feature-values ")
+	for i, family := range r.FontFamilies {
+		if i > 0 {
+			p.buf.WriteByte(',')
+			p.space()
+		}
+		p.buf.WriteString(strconv.Quote(string(family)))
+	}
+	p.space()
+	p.buf.WriteByte('{')
+	p.depth++
+	for _, block := range r.Blocks {
+		p.newline()
+		p.addMapping(block)
+		p.buf.WriteByte('@')
+		p.buf.Write(block.Name)
+		if err := p.printDeclarationBlock(block.Declarations); err != nil {
+			return err
+		}
+	}
+	p.depth--
+	if len(r.Blocks) > 0 {
+		p.newline()
+	}
+	p.buf.WriteByte('}')
+	return nil
+}
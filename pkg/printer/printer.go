@@ -0,0 +1,480 @@
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+	"github.com/aledsdavies/pristinecss/pkg/sourcemap"
+)
+
+type PrinterOpt func(*printerOptions)
+
+type printerOptions struct {
+	compact           bool
+	indent            string
+	omitLastSemicolon bool
+	sourceMap         *sourcemap.Generator
+}
+
+// WithCompact prints the CSS without optional whitespace
+func WithCompact() PrinterOpt {
+	return func(opts *printerOptions) {
+		opts.compact = true
+	}
+}
+
+// WithIndent sets the string used for each level of indentation when pretty printing
+func WithIndent(indent string) PrinterOpt {
+	return func(opts *printerOptions) {
+		opts.indent = indent
+	}
+}
+
+// WithoutLastSemicolon omits the optional semicolon after the last declaration in a block
+func WithoutLastSemicolon() PrinterOpt {
+	return func(opts *printerOptions) {
+		opts.omitLastSemicolon = true
+	}
+}
+
+// WithSourceMap adds a mapping to the generator for every rule, declaration and comment printed,
+// and for the queries, conditions and blocks of at-rules, from its position in the output to the
+// position recorded when it was parsed. Nodes parsed from
+// different files, such as inlined imports, map back to their own sources.
+func WithSourceMap(g *sourcemap.Generator) PrinterOpt {
+	return func(opts *printerOptions) {
+		opts.sourceMap = g
+	}
+}
+
+// Print returns the CSS for the node.
+//
+// Parameters:
+// - node: The node to print, usually a *parser.Stylesheet.
+// - opts: Options to configure the output.
+//
+// Returns:
+// - The CSS as a string.
+// - An error if the node, or one of its children, is of a type the printer does not support.
+func Print(node parser.Node, opts ...PrinterOpt) (string, error) {
+	var sb strings.Builder
+	if err := Fprint(&sb, node, opts...); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// Fprint writes the CSS for the node to w.
+func Fprint(w io.Writer, node parser.Node, opts ...PrinterOpt) error {
+	options := &printerOptions{
+		indent: "  ",
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	p := &printer{options: options}
+	if err := p.printNode(node); err != nil {
+		return err
+	}
+
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+type printer struct {
+	buf     bytes.Buffer
+	options *printerOptions
+	depth   int
+
+	// The generated line and column at offset scanned of buf, tracked for source maps
+	scanned     int
+	line        int
+	column      int
+	lastMapping sourcemap.Mapping
+}
+
+func (p *printer) printNode(node parser.Node) error {
+	p.addMapping(node)
+	switch n := node.(type) {
+	case *parser.Stylesheet:
+		return p.printStylesheet(n)
+	case *parser.Comment:
+		p.buf.Write(n.Text)
+	case *parser.Selector:
+		return p.printSelector(n)
+	case *parser.Declaration:
+		return p.printDeclaration(n)
+	case parser.Value:
+		return p.printValue(n)
+	case parser.AtRule:
+		return p.printAtRule(n)
+	default:
+		return fmt.Errorf("printer: unsupported node type %T", node)
+	}
+	return nil
+}
+
+func (p *printer) printStylesheet(s *parser.Stylesheet) error {
+	for i, rule := range s.Rules {
+		if rule == nil {
+			continue
+		}
+		if i > 0 {
+			p.newline()
+		}
+		if err := p.printNode(rule); err != nil {
+			return err
+		}
+	}
+	if !p.options.compact && len(s.Rules) > 0 {
+		p.buf.WriteByte('\n')
+	}
+	return nil
+}
+
+func (p *printer) printSelector(s *parser.Selector) error {
+	if err := p.printSelectorList(s.Selectors); err != nil {
+		return err
+	}
+	return p.printBlock(s.Rules)
+}
+
+// printSelectorList prints the complex selectors of a list separated by commas.
+func (p *printer) printSelectorList(list parser.SelectorList) error {
+	for i, complex := range list {
+		if i > 0 {
+			p.buf.WriteByte(',')
+			p.space()
+		}
+		if err := p.printComplexSelector(complex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *printer) printComplexSelector(complex *parser.ComplexSelector) error {
+	for i, compound := range complex.Compounds {
+		p.printCombinator(compound.Combinator, i == 0)
+		for _, simple := range compound.Selectors {
+			if err := p.printSimpleSelector(simple); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// printCombinator prints the combinator before a compound selector. A leading combinator, as in
+// a relative selector, is only followed by a space.
+func (p *printer) printCombinator(combinator parser.CombinatorType, first bool) {
+	switch combinator {
+	case parser.NoCombinator:
+	case parser.Descendant:
+		p.buf.WriteByte(' ')
+	default:
+		if !first {
+			p.space()
+		}
+		p.buf.WriteString(string(combinator))
+		p.space()
+	}
+}
+
+func (p *printer) printSimpleSelector(simple parser.SimpleSelector) error {
+	switch s := simple.(type) {
+	case *parser.TypeSelector:
+		p.printNamespacePrefix(s.Namespace)
+		p.buf.Write(s.Name)
+	case *parser.UniversalSelector:
+		p.printNamespacePrefix(s.Namespace)
+		p.buf.WriteByte('*')
+	case *parser.NestingSelector:
+		p.buf.WriteByte('&')
+	case *parser.ClassSelector:
+		p.buf.WriteByte('.')
+		p.buf.Write(s.Name)
+	case *parser.IDSelector:
+		p.buf.WriteByte('#')
+		p.buf.Write(s.Name)
+	case *parser.AttributeSelector:
+		p.printAttributeSelector(s)
+	case *parser.PseudoSelector:
+		p.buf.WriteByte(':')
+		if s.Element {
+			p.buf.WriteByte(':')
+		}
+		p.buf.Write(s.Name)
+		if s.Functional {
+			p.buf.WriteByte('(')
+			if err := p.printPseudoArguments(s); err != nil {
+				return err
+			}
+			p.buf.WriteByte(')')
+		}
+	default:
+		return fmt.Errorf("printer: unsupported selector type %T", simple)
+	}
+	return nil
+}
+
+func (p *printer) printPseudoArguments(pseudo *parser.PseudoSelector) error {
+	switch {
+	case pseudo.Selectors != nil:
+		return p.printSelectorList(pseudo.Selectors)
+	case pseudo.Nth != nil:
+		p.buf.WriteString(formatAnPlusB(pseudo.Nth.A, pseudo.Nth.B))
+		if pseudo.Nth.Of != nil {
+			p.buf.WriteString(" of ")
+			return p.printSelectorList(pseudo.Nth.Of)
+		}
+	default:
+		p.buf.Write(pseudo.Arguments)
+	}
+	return nil
+}
+
+// formatAnPlusB returns the shortest form of An+B, such as "2n+1", "-n+3" or "5".
+func formatAnPlusB(a, b int) string {
+	var sb strings.Builder
+	switch a {
+	case 0:
+		return strconv.Itoa(b)
+	case 1:
+		sb.WriteString("n")
+	case -1:
+		sb.WriteString("-n")
+	default:
+		sb.WriteString(strconv.Itoa(a) + "n")
+	}
+	if b > 0 {
+		sb.WriteByte('+')
+	}
+	if b != 0 {
+		sb.WriteString(strconv.Itoa(b))
+	}
+	return sb.String()
+}
+
+func (p *printer) printAttributeSelector(attr *parser.AttributeSelector) {
+	p.buf.WriteByte('[')
+	p.printNamespacePrefix(attr.Namespace)
+	p.buf.Write(attr.Name)
+	if attr.Matcher != parser.MatchExists {
+		p.buf.WriteString(string(attr.Matcher))
+		p.printAttributeValue(attr.Value)
+		if attr.Modifier != parser.NoModifier {
+			p.buf.WriteByte(' ')
+			p.buf.WriteString(string(attr.Modifier))
+		}
+	}
+	p.buf.WriteByte(']')
+}
+
+// printAttributeValue prints the value of an attribute selector, quoted unless it is an
+// identifier. Values hold their escapes as written, so only the choice of quote can clash.
+func (p *printer) printAttributeValue(value []byte) {
+	if isIdentifier(value) {
+		p.buf.Write(value)
+		return
+	}
+	quote := byte('"')
+	if bytes.IndexByte(value, '"') >= 0 {
+		quote = '\''
+	}
+	p.buf.WriteByte(quote)
+	p.buf.Write(value)
+	p.buf.WriteByte(quote)
+}
+
+func (p *printer) printNamespacePrefix(namespace *parser.NamespacePrefix) {
+	if namespace != nil {
+		p.buf.Write(namespace.Name)
+		p.buf.WriteByte('|')
+	}
+}
+
+// printBlock prints the rules between braces, one per line when pretty printing.
+func (p *printer) printBlock(rules []parser.Node) error {
+	p.space()
+	p.buf.WriteByte('{')
+	p.depth++
+	for _, rule := range rules {
+		p.newline()
+		if err := p.printNode(rule); err != nil {
+			return err
+		}
+	}
+	p.depth--
+	if len(rules) > 0 {
+		if _, ok := rules[len(rules)-1].(*parser.Declaration); ok && p.options.omitLastSemicolon {
+			p.buf.Truncate(p.buf.Len() - 1)
+		}
+		p.newline()
+	}
+	p.buf.WriteByte('}')
+	return nil
+}
+
+func (p *printer) printDeclaration(d *parser.Declaration) error {
+	p.buf.Write(d.Key)
+	p.buf.WriteByte(':')
+	p.space()
+	if err := p.printValues(d.Value); err != nil {
+		return err
+	}
+	if d.Important {
+		p.space()
+		p.buf.WriteString("!important")
+	}
+	p.buf.WriteByte(';')
+	return nil
+}
+
+// printValues prints a list of component values separated by spaces. Commas are attached to
+// the preceding value.
+func (p *printer) printValues(values []parser.Value) error {
+	for i, value := range values {
+		if i > 0 && p.needsSpace(values[i-1], value) {
+			p.buf.WriteByte(' ')
+		}
+		if err := p.printValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// needsSpace reports whether a space is printed between two adjacent component values.
+func (p *printer) needsSpace(prev, next parser.Value) bool {
+	if isComma(next) {
+		return false
+	}
+	if p.options.compact && (isSeparator(prev) || isSeparator(next)) {
+		return false
+	}
+	return true
+}
+
+func (p *printer) printValue(value parser.Value) error {
+	switch v := value.(type) {
+	case *parser.BasicValue:
+		p.buf.Write(v.Value)
+	case *parser.StringValue:
+		quote := byte('"')
+		if v.SingleQuote {
+			quote = '\''
+		}
+		p.buf.WriteByte(quote)
+		p.buf.Write(v.Value)
+		p.buf.WriteByte(quote)
+	case *parser.FunctionValue:
+		p.buf.Write(v.Name)
+		p.buf.WriteByte('(')
+		if err := p.printValues(v.Arguments); err != nil {
+			return err
+		}
+		p.buf.WriteByte(')')
+	case *parser.Comment:
+		p.buf.Write(v.Text)
+	default:
+		return fmt.Errorf("printer: unsupported value type %T", value)
+	}
+	return nil
+}
+
+// isComma reports whether the value is a comma separating component values.
+func isComma(value parser.Value) bool {
+	bv, ok := value.(*parser.BasicValue)
+	return ok && string(bv.Value) == ","
+}
+
+// isIdentifier reports whether the value can be written as a CSS identifier without escapes.
+func isIdentifier(value []byte) bool {
+	name := bytes.TrimPrefix(value, []byte("-"))
+	if len(name) == 0 {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 0x80, c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c == '-' && i == 0 && len(value) > len(name):
+			// Names starting with two dashes, like custom properties, are identifiers
+		case (c == '-' || c >= '0' && c <= '9') && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isSeparator reports whether the value is a comma or slash, around which whitespace is optional.
+func isSeparator(value parser.Value) bool {
+	bv, ok := value.(*parser.BasicValue)
+	return ok && (string(bv.Value) == "," || string(bv.Value) == "/")
+}
+
+// addMapping records a source map mapping from the current output position to the start of the
+// node in its original source. The node can be any part of the tree that records its position,
+// such as a media query. A mapping that repeats the last one, as when a stylesheet and its first
+// rule start at the same place, is not added again.
+func (p *printer) addMapping(node any) {
+	if p.options.sourceMap == nil {
+		return
+	}
+	positioned, ok := node.(parser.Positioned)
+	if !ok || positioned.Pos().Line == 0 {
+		return
+	}
+
+	if p.line == 0 {
+		p.line, p.column = 1, 1
+	}
+	for _, ch := range p.buf.Bytes()[p.scanned:] {
+		if ch == '\n' {
+			p.line++
+			p.column = 1
+		} else {
+			p.column++
+		}
+	}
+	p.scanned = p.buf.Len()
+
+	pos := positioned.Pos()
+	mapping := sourcemap.Mapping{
+		GeneratedLine:   p.line,
+		GeneratedColumn: p.column,
+		Source:          pos.Source,
+		OriginalLine:    pos.Line,
+		OriginalColumn:  pos.Column,
+	}
+	if mapping == p.lastMapping {
+		return
+	}
+	p.lastMapping = mapping
+	p.options.sourceMap.AddMapping(mapping)
+}
+
+// space writes a space unless printing compactly.
+func (p *printer) space() {
+	if !p.options.compact {
+		p.buf.WriteByte(' ')
+	}
+}
+
+// newline starts a new, indented line unless printing compactly.
+func (p *printer) newline() {
+	if p.options.compact {
+		return
+	}
+	p.buf.WriteByte('\n')
+	for i := 0; i < p.depth; i++ {
+		p.buf.WriteString(p.options.indent)
+	}
+}
@@ -0,0 +1,317 @@
+package printer_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+	"github.com/aledsdavies/pristinecss/pkg/printer"
+	"github.com/aledsdavies/pristinecss/pkg/sourcemap"
+	"github.com/google/go-cmp/cmp"
+)
+
+func parse(t *testing.T, input string) *parser.Stylesheet {
+	t.Helper()
+	stylesheet, errors := parser.Parse(lexer.Lex(strings.NewReader(input)))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected parse errors: %v", errors)
+	}
+	return stylesheet
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		pretty  string
+		compact string
+	}{
+		{
+			name:    "Selector with declarations",
+			input:   "div.container > p, a:hover::before { color: blue; margin: 0 auto !important; }",
+			pretty:  "div.container > p, a:hover::before {\n  color: blue;\n  margin: 0 auto !important;\n}\n",
+			compact: "div.container>p,a:hover::before{color:blue;margin:0 auto!important;}",
+		},
+		{
+			name:    "Layers",
+			input:   "@layer reset, base; @layer base { @supports (display: grid) { .a { display: grid; } } }",
+			pretty:  "@layer reset, base;\n@layer base {\n  @supports (display: grid) {\n    .a {\n      display: grid;\n    }\n  }\n}\n",
+			compact: "@layer reset,base;@layer base{@supports (display:grid){.a{display:grid;}}}",
+		},
+		{
+			name:    "Supports with combined conditions",
+			input:   "@supports (not (display: grid)) or (display: flex) { .a { float: left; } }",
+			pretty:  "@supports (not (display: grid)) or (display: flex) {\n  .a {\n    float: left;\n  }\n}\n",
+			compact: "@supports (not (display:grid)) or (display:flex){.a{float:left;}}",
+		},
+		{
+			name:    "Descendant type selector",
+			input:   "article p { line-height: 1.5; }",
+			pretty:  "article p {\n  line-height: 1.5;\n}\n",
+			compact: "article p{line-height:1.5;}",
+		},
+		{
+			name:    "Descendant class selectors",
+			input:   ".nav .item, .nav.item :hover { color: red; }",
+			pretty:  ".nav .item, .nav.item :hover {\n  color: red;\n}\n",
+			compact: ".nav .item,.nav.item :hover{color:red;}",
+		},
+		{
+			name:    "Combinators and pseudo-selectors",
+			input:   "ul>li + *~a:not(.b)::before { color: red; }",
+			pretty:  "ul > li + * ~ a:not(.b)::before {\n  color: red;\n}\n",
+			compact: "ul>li+*~a:not(.b)::before{color:red;}",
+		},
+		{
+			name:    "Namespaces",
+			input:   "@namespace svg url(http://www.w3.org/2000/svg); svg|rect, *|*, |p[svg|x='1'] { fill: red; }",
+			pretty:  "@namespace svg url(http://www.w3.org/2000/svg);\nsvg|rect, *|*, |p[svg|x=\"1\"] {\n  fill: red;\n}\n",
+			compact: "@namespace svg url(http://www.w3.org/2000/svg);svg|rect,*|*,|p[svg|x=\"1\"]{fill:red;}",
+		},
+		{
+			name:    "Attribute selectors",
+			input:   "[href] , a[ href ^= 'http' i ][rel~=\"a b\"][title='say \"hi\"'][lang|=en s] { color: red; }",
+			pretty:  "[href], a[href^=http i][rel~=\"a b\"][title='say \"hi\"'][lang|=en s] {\n  color: red;\n}\n",
+			compact: "[href],a[href^=http i][rel~=\"a b\"][title='say \"hi\"'][lang|=en s]{color:red;}",
+		},
+		{
+			name:    "Pseudo-class arguments",
+			input:   "a:is(h1,.b>c):has(> img , + p):nth-child( odd of .x , .y ):nth-last-of-type(-n + 3):nth-of-type(even):lang(en) { color: red; }",
+			pretty:  "a:is(h1, .b > c):has(> img, + p):nth-child(2n+1 of .x, .y):nth-last-of-type(-n+3):nth-of-type(2n):lang(en) {\n  color: red;\n}\n",
+			compact: "a:is(h1,.b>c):has(>img,+p):nth-child(2n+1 of .x,.y):nth-last-of-type(-n+3):nth-of-type(2n):lang(en){color:red;}",
+		},
+		{
+			name:    "Nested style rules",
+			input:   ".card { color: red; &:hover { color: blue; } >li , .a &{ margin: 0; } @media (min-width: 600px) { padding: 0; } }",
+			pretty:  ".card {\n  color: red;\n  &:hover {\n    color: blue;\n  }\n  > li, .a & {\n    margin: 0;\n  }\n  @media (min-width: 600px) {\n    padding: 0;\n  }\n}\n",
+			compact: ".card{color:red;&:hover{color:blue;}>li,.a &{margin:0;}@media (min-width:600px){padding:0;}}",
+		},
+		{
+			name:    "Function and comma separated values",
+			input:   "body { font-family: Arial, sans-serif; background: linear-gradient(to right, rgb(255,0,0), rgba(0, 0, 255, 0.5)); width: calc(100% - 20px); }",
+			pretty:  "body {\n  font-family: Arial, sans-serif;\n  background: linear-gradient(to right, rgb(255, 0, 0), rgba(0, 0, 255, 0.5));\n  width: calc(100% - 20px);\n}\n",
+			compact: "body{font-family:Arial,sans-serif;background:linear-gradient(to right,rgb(255,0,0),rgba(0,0,255,0.5));width:calc(100% - 20px);}",
+		},
+		{
+			name:    "Numbers with exponents",
+			input:   ".a { width: 1e3px; margin: 1.5E-2em 2e+1% 0; }",
+			pretty:  ".a {\n  width: 1e3px;\n  margin: 1.5E-2em 2e+1% 0;\n}\n",
+			compact: ".a{width:1e3px;margin:1.5E-2em 2e+1% 0;}",
+		},
+		{
+			name:    "Dimensions with any unit",
+			input:   ".a { background: image-set(url(a.png) 2x, url(b.png) 1.5DPPX); margin: 0PX 1Q 2fr; }",
+			pretty:  ".a {\n  background: image-set(url(a.png) 2x, url(b.png) 1.5DPPX);\n  margin: 0PX 1Q 2fr;\n}\n",
+			compact: ".a{background:image-set(url(a.png) 2x,url(b.png) 1.5DPPX);margin:0PX 1Q 2fr;}",
+		},
+		{
+			name:    "Strings, urls and comments",
+			input:   "/* header */ .icon { content: '→'; background: url(\"a.png\"), url(b.png); /* note */ }",
+			pretty:  "/* header */\n.icon {\n  content: '→';\n  background: url(\"a.png\"), url(b.png);\n  /* note */\n}\n",
+			compact: "/* header */.icon{content:'→';background:url(\"a.png\"),url(b.png);/* note */}",
+		},
+		{
+			name:    "Media query",
+			input:   "@media screen and (min-width: 768px), print { .sidebar { display: none; } }",
+			pretty:  "@media screen and (min-width: 768px), print {\n  .sidebar {\n    display: none;\n  }\n}\n",
+			compact: "@media screen and (min-width:768px),print{.sidebar{display:none;}}",
+		},
+		{
+			name:    "Import",
+			input:   `@import url("complex.css") layer(utilities) supports((display: flex) and (not (color: green))) screen and (min-width: 1024px);`,
+			pretty:  "@import url(\"complex.css\") layer(utilities) supports((display: flex) and (not (color: green))) screen and (min-width: 1024px);\n",
+			compact: "@import url(\"complex.css\") layer(utilities) supports((display:flex) and (not (color:green))) screen and (min-width:1024px);",
+		},
+		{
+			name:    "Keyframes",
+			input:   "@-webkit-keyframes bounce { from, 50% { opacity: 0; } to { opacity: 1; } }",
+			pretty:  "@-webkit-keyframes bounce {\n  from, 50% {\n    opacity: 0;\n  }\n  to {\n    opacity: 1;\n  }\n}\n",
+			compact: "@-webkit-keyframes bounce{from,50%{opacity:0;}to{opacity:1;}}",
+		},
+		{
+			name:    "Container",
+			input:   "@container sidebar (min-width: 400px) and (max-width: 800px) { .card { display: grid; } }",
+			pretty:  "@container sidebar (min-width: 400px) and (max-width: 800px) {\n  .card {\n    display: grid;\n  }\n}\n",
+			compact: "@container sidebar (min-width:400px) and (max-width:800px){.card{display:grid;}}",
+		},
+		{
+			name:    "Font feature values",
+			input:   "@font-feature-values Font One { @styleset { nice-style: 12; } }",
+			pretty:  "@font-feature-values \"Font One\" {\n  @styleset {\n    nice-style: 12;\n  }\n}\n",
+			compact: "@font-feature-values \"Font One\"{@styleset{nice-style:12;}}",
+		},
+		{
+			name:    "Charset and font face",
+			input:   "@charset \"UTF-8\"; @font-face { font-family: \"Open Sans\"; src: url(a.woff2) format(\"woff2\"); }",
+			pretty:  "@charset \"UTF-8\";\n@font-face {\n  font-family: \"Open Sans\";\n  src: url(a.woff2) format(\"woff2\");\n}\n",
+			compact: "@charset \"UTF-8\";@font-face{font-family:\"Open Sans\";src:url(a.woff2) format(\"woff2\");}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stylesheet := parse(t, tt.input)
+
+			pretty, err := printer.Print(stylesheet)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.pretty, pretty); diff != "" {
+				t.Errorf("Pretty output mismatch (-want +got):\n%s", diff)
+			}
+
+			compact, err := printer.Print(stylesheet, printer.WithCompact())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.compact, compact); diff != "" {
+				t.Errorf("Compact output mismatch (-want +got):\n%s", diff)
+			}
+
+			// Both outputs must parse back to the same tree
+			expected := stylesheet.String()
+			for _, output := range []string{pretty, compact} {
+				if diff := cmp.Diff(expected, parse(t, output).String()); diff != "" {
+					t.Errorf("Round trip mismatch for %q (-want +got):\n%s", output, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintFrameworksRoundTrip(t *testing.T) {
+	frameworks := []struct {
+		name string
+		path string
+	}{
+		{"Bootstrap", filepath.Join("..", "..", "test-data", "frameworks", "bootstrap.css")},
+		{"Bulma", filepath.Join("..", "..", "test-data", "frameworks", "bulma.css")},
+		{"Foundation", filepath.Join("..", "..", "test-data", "frameworks", "foundation.css")},
+		{"Materialize", filepath.Join("..", "..", "test-data", "frameworks", "materialize.css")},
+		{"Spectre", filepath.Join("..", "..", "test-data", "frameworks", "spectre.css")},
+	}
+
+	for _, fw := range frameworks {
+		t.Run(fw.name, func(t *testing.T) {
+			file, err := os.Open(fw.path)
+			if err != nil {
+				t.Fatalf("Could not open the file %s: %v", fw.path, err)
+			}
+			defer file.Close()
+
+			stylesheet, _ := parser.Parse(lexer.Lex(file))
+			first, err := printer.Print(stylesheet)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// Printing the reparsed output must be stable
+			reparsed, _ := parser.Parse(lexer.Lex(strings.NewReader(first)))
+			second, err := printer.Print(reparsed)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if first != second {
+				t.Errorf("Printing %s is not stable across a round trip", fw.name)
+			}
+		})
+	}
+}
+
+func TestPrintSourceMap(t *testing.T) {
+	stylesheet, errors := parser.Parse(lexer.Lex(strings.NewReader(".a { color: red; }\n.b { margin: 0; }")), parser.WithSource("main.css"))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected parse errors: %v", errors)
+	}
+
+	// Rules inlined from an import keep the source they were parsed from
+	stylesheet.Rules = append(stylesheet.Rules, &parser.Selector{
+		Position:  parser.Position{Source: "reset.css", Line: 3, Column: 1},
+		Selectors: parser.SelectorList{{Compounds: []*parser.CompoundSelector{{Selectors: []parser.SimpleSelector{&parser.TypeSelector{Name: []byte("body")}}}}}},
+	})
+
+	g := sourcemap.New("out.css")
+	output, err := printer.Print(stylesheet, printer.WithCompact(), printer.WithSourceMap(g))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(".a{color:red;}.b{margin:0;}body{}", output); diff != "" {
+		t.Fatalf("Output mismatch (-want +got):\n%s", diff)
+	}
+
+	expected := []sourcemap.Mapping{
+		{GeneratedLine: 1, GeneratedColumn: 1, Source: "main.css", OriginalLine: 1, OriginalColumn: 1},
+		{GeneratedLine: 1, GeneratedColumn: 4, Source: "main.css", OriginalLine: 1, OriginalColumn: 6},
+		{GeneratedLine: 1, GeneratedColumn: 15, Source: "main.css", OriginalLine: 2, OriginalColumn: 1},
+		{GeneratedLine: 1, GeneratedColumn: 18, Source: "main.css", OriginalLine: 2, OriginalColumn: 6},
+		{GeneratedLine: 1, GeneratedColumn: 28, Source: "reset.css", OriginalLine: 3, OriginalColumn: 1},
+	}
+	if diff := cmp.Diff(expected, g.Mappings()); diff != "" {
+		t.Errorf("Mappings mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"main.css", "reset.css"}, g.Sources()); diff != "" {
+		t.Errorf("Sources mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrintSourceMapAtRules(t *testing.T) {
+	input := "@media screen and (min-width: 600px) {}\n" +
+		"@container (min-width: 1px) and (max-width: 2px) {}\n" +
+		"@supports not (display: grid) {}\n" +
+		"@keyframes k { from { opacity: 0; } }\n" +
+		"@font-feature-values F { @swash { a: 1; } }"
+	stylesheet, errors := parser.Parse(lexer.Lex(strings.NewReader(input)), parser.WithSource("main.css"))
+	if len(errors) > 0 {
+		t.Fatalf("Unexpected parse errors: %v", errors)
+	}
+
+	g := sourcemap.New("out.css")
+	output, err := printer.Print(stylesheet, printer.WithCompact(), printer.WithSourceMap(g))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedOutput := "@media screen and (min-width:600px){}" +
+		"@container (min-width:1px) and (max-width:2px){}" +
+		"@supports not (display:grid){}" +
+		"@keyframes k{from{opacity:0;}}" +
+		"@font-feature-values \"F\"{@swash{a:1;}}"
+	if diff := cmp.Diff(expectedOutput, output); diff != "" {
+		t.Fatalf("Output mismatch (-want +got):\n%s", diff)
+	}
+
+	// The stylesheet maps to the same place as its first rule, so it adds no mapping of its own
+	mapping := func(generatedColumn, originalLine, originalColumn int) sourcemap.Mapping {
+		return sourcemap.Mapping{
+			GeneratedLine:   1,
+			GeneratedColumn: generatedColumn,
+			Source:          "main.css",
+			OriginalLine:    originalLine,
+			OriginalColumn:  originalColumn,
+		}
+	}
+	expected := []sourcemap.Mapping{
+		mapping(1, 1, 1),    // @media
+		mapping(8, 1, 8),    // screen and (min-width: 600px)
+		mapping(19, 1, 19),  // (min-width: 600px)
+		mapping(38, 2, 1),   // @container
+		mapping(49, 2, 12),  // (min-width: 1px)
+		mapping(50, 2, 13),  // min-width: 1px
+		mapping(69, 2, 33),  // (max-width: 2px)
+		mapping(70, 2, 34),  // max-width: 2px
+		mapping(86, 3, 1),   // @supports
+		mapping(96, 3, 11),  // not (display: grid)
+		mapping(101, 3, 16), // display: grid
+		mapping(116, 4, 1),  // @keyframes
+		mapping(129, 4, 16), // from
+		mapping(134, 4, 23), // opacity: 0
+		mapping(146, 5, 1),  // @font-feature-values
+		mapping(171, 5, 26), // @swash
+		mapping(178, 5, 35), // a: 1
+	}
+	if diff := cmp.Diff(expected, g.Mappings()); diff != "" {
+		t.Errorf("Mappings mismatch (-want +got):\n%s", diff)
+	}
+}
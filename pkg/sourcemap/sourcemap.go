@@ -0,0 +1,181 @@
+// Package sourcemap generates version 3 source maps, which map positions in generated CSS back
+// to the original sources.
+package sourcemap
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const base64Digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// Mapping maps a position in the generated file to a position in an original source. Lines and
+// columns are 1-based, matching the positions recorded by the lexer.
+type Mapping struct {
+	GeneratedLine   int
+	GeneratedColumn int
+	Source          string
+	OriginalLine    int
+	OriginalColumn  int
+}
+
+// Generator collects mappings and encodes them as a source map.
+type Generator struct {
+	file           string
+	mappings       []Mapping
+	sources        []string
+	sourceIndex    map[string]int
+	sourcesContent map[string]string
+}
+
+// New returns a generator for a source map of the generated file.
+//
+// Parameters:
+// - file: The name of the generated file, recorded in the map's file field. It may be empty.
+//
+// Returns:
+// - A generator with no mappings.
+func New(file string) *Generator {
+	return &Generator{
+		file:           file,
+		sourceIndex:    make(map[string]int),
+		sourcesContent: make(map[string]string),
+	}
+}
+
+// AddMapping records a mapping. Mappings can be added in any order.
+func (g *Generator) AddMapping(m Mapping) {
+	g.addSource(m.Source)
+	g.mappings = append(g.mappings, m)
+}
+
+// SetSourceContent embeds the content of an original source in the map, so that it can be shown
+// without the original file being served.
+func (g *Generator) SetSourceContent(source string, content []byte) {
+	g.addSource(source)
+	g.sourcesContent[source] = string(content)
+}
+
+// Mappings returns the mappings in the order they were added.
+func (g *Generator) Mappings() []Mapping {
+	return append([]Mapping{}, g.mappings...)
+}
+
+// Sources returns the names of the original sources in the order they were first added.
+func (g *Generator) Sources() []string {
+	return append([]string{}, g.sources...)
+}
+
+func (g *Generator) addSource(source string) {
+	if _, ok := g.sourceIndex[source]; !ok {
+		g.sourceIndex[source] = len(g.sources)
+		g.sources = append(g.sources, source)
+	}
+}
+
+// sourceMap is the JSON representation of a version 3 source map.
+type sourceMap struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file,omitempty"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent,omitempty"`
+	Names          []string  `json:"names"`
+	Mappings       string    `json:"mappings"`
+}
+
+// MarshalJSON encodes the source map.
+func (g *Generator) MarshalJSON() ([]byte, error) {
+	sm := sourceMap{
+		Version:  3,
+		File:     g.file,
+		Sources:  g.Sources(),
+		Names:    []string{},
+		Mappings: g.encodeMappings(),
+	}
+	if len(g.sourcesContent) > 0 {
+		sm.SourcesContent = make([]*string, len(g.sources))
+		for i, source := range g.sources {
+			if content, ok := g.sourcesContent[source]; ok {
+				sm.SourcesContent[i] = &content
+			}
+		}
+	}
+	return json.Marshal(sm)
+}
+
+// DataURL returns the source map encoded as a base64 data URL, for use in an inline
+// sourceMappingURL comment.
+func (g *Generator) DataURL() (string, error) {
+	data, err := g.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	return "data:application/json;charset=utf-8;base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// URLComment returns the CSS comment that links a generated file to its source map.
+func URLComment(url string) string {
+	return "/*# sourceMappingURL=" + url + " */"
+}
+
+// encodeMappings encodes the mappings as base64 VLQ segments, grouped by generated line.
+func (g *Generator) encodeMappings() string {
+	mappings := append([]Mapping{}, g.mappings...)
+	sort.SliceStable(mappings, func(i, j int) bool {
+		if mappings[i].GeneratedLine != mappings[j].GeneratedLine {
+			return mappings[i].GeneratedLine < mappings[j].GeneratedLine
+		}
+		return mappings[i].GeneratedColumn < mappings[j].GeneratedColumn
+	})
+
+	var sb strings.Builder
+	line := 1
+	var prevColumn, prevSource, prevOriginalLine, prevOriginalColumn int
+	for i, m := range mappings {
+		if i > 0 && m == mappings[i-1] {
+			continue
+		}
+		if m.GeneratedLine != line {
+			for line < m.GeneratedLine {
+				sb.WriteByte(';')
+				line++
+			}
+			prevColumn = 0
+		} else if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		source := g.sourceIndex[m.Source]
+		writeVLQ(&sb, m.GeneratedColumn-1-prevColumn)
+		writeVLQ(&sb, source-prevSource)
+		writeVLQ(&sb, m.OriginalLine-1-prevOriginalLine)
+		writeVLQ(&sb, m.OriginalColumn-1-prevOriginalColumn)
+
+		prevColumn = m.GeneratedColumn - 1
+		prevSource = source
+		prevOriginalLine = m.OriginalLine - 1
+		prevOriginalColumn = m.OriginalColumn - 1
+	}
+	return sb.String()
+}
+
+// writeVLQ writes the value as a base64 variable length quantity, with the sign in the lowest bit.
+func writeVLQ(sb *strings.Builder, value int) {
+	vlq := value << 1
+	if value < 0 {
+		vlq = (-value << 1) | 1
+	}
+	for {
+		digit := vlq & 0x1f
+		vlq >>= 5
+		if vlq > 0 {
+			digit |= 0x20
+		}
+		sb.WriteByte(base64Digits[digit])
+		if vlq == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package sourcemap_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/sourcemap"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	g := sourcemap.New("out.css")
+	g.SetSourceContent("a.css", []byte(".a{}"))
+	g.AddMapping(sourcemap.Mapping{GeneratedLine: 1, GeneratedColumn: 5, Source: "a.css", OriginalLine: 2, OriginalColumn: 3})
+	g.AddMapping(sourcemap.Mapping{GeneratedLine: 1, GeneratedColumn: 1, Source: "a.css", OriginalLine: 1, OriginalColumn: 1})
+	g.AddMapping(sourcemap.Mapping{GeneratedLine: 3, GeneratedColumn: 17, Source: "b.css", OriginalLine: 1, OriginalColumn: 1})
+
+	data, err := g.MarshalJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"version":3,"file":"out.css","sources":["a.css","b.css"],"sourcesContent":[".a{}",null],"names":[],"mappings":"AAAA,IACE;;gBCDF"}`
+	if diff := cmp.Diff(expected, string(data)); diff != "" {
+		t.Errorf("Source map mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDataURL(t *testing.T) {
+	g := sourcemap.New("out.css")
+	g.AddMapping(sourcemap.Mapping{GeneratedLine: 1, GeneratedColumn: 1, Source: "a.css", OriginalLine: 1, OriginalColumn: 1})
+
+	url, err := g.DataURL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	prefix := "data:application/json;charset=utf-8;base64,"
+	if !strings.HasPrefix(url, prefix) {
+		t.Fatalf("Expected a base64 JSON data URL, got %q", url)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(url, prefix))
+	if err != nil {
+		t.Fatalf("Could not decode data URL: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Could not decode source map: %v", err)
+	}
+	if decoded["mappings"] != "AAAA" {
+		t.Errorf("Expected mappings %q, got %q", "AAAA", decoded["mappings"])
+	}
+}
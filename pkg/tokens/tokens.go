@@ -46,15 +46,63 @@ const (
 	RBRACKET = "]"
 	LBRACE   = "{"
 	RBRACE   = "}"
+
+	// CSS Syntax Level 3 tokens, produced by the lexer's spec mode. In spec mode URI is a
+	// url-token, PERCENTAGE is a percentage-token such as 50%, STARTS_WITH is the ^= prefix-match
+	// and any other single code point is a DELIM.
+
+	WHITESPACE      = "WHITESPACE"
+	FUNCTION        = "FUNCTION"
+	AT_KEYWORD      = "AT_KEYWORD"
+	DIMENSION       = "DIMENSION"
+	UNICODE_RANGE   = "UNICODE_RANGE"
+	BAD_STRING      = "BAD_STRING"
+	BAD_URL         = "BAD_URL"
+	DELIM           = "DELIM"
+	CDO             = "<!--"
+	CDC             = "-->"
+	INCLUDE_MATCH   = "~="
+	DASH_MATCH      = "|="
+	SUFFIX_MATCH    = "$="
+	SUBSTRING_MATCH = "*="
+	COLUMN          = "||"
 )
 
 type TokenType string
 
+// TokenFlag is the type flag of HASH and numeric tokens in the lexer's spec mode.
+type TokenFlag int
+
+const (
+	NoFlag TokenFlag = iota
+	// FlagID marks a HASH whose name is an identifier, such as #main, so it can be an ID selector
+	FlagID
+	// FlagUnrestricted marks a HASH whose name is not an identifier, such as #123
+	FlagUnrestricted
+	// FlagInteger marks a numeric token without a fraction or exponent
+	FlagInteger
+	// FlagNumber marks a numeric token with a fraction or exponent
+	FlagNumber
+)
+
 type Token struct {
 	Type    TokenType
 	Literal []byte
 	Line    int
 	Column  int
+	// Offset is the 0-based byte offset of the token's first byte in the input
+	Offset int
+	// End is the byte offset just past the token's last byte, so the token spans input[Offset:End]
+	End int
+
+	// Unit is the unit of a DIMENSION token, which is also the end of its Literal
+	Unit []byte
+	// Flag is the type flag of a HASH or numeric token in spec mode
+	Flag TokenFlag
+	// SpaceBefore is true if whitespace preceded the token. The default mode drops whitespace, so
+	// this is what tells the descendant combinator in "a .b" apart from "a.b". It is not set in
+	// spec mode, which keeps whitespace as WHITESPACE tokens.
+	SpaceBefore bool
 }
 
 // Token needs to implement the Erasable interface
@@ -63,6 +111,11 @@ func (t *Token) Erase() {
 	t.Literal = t.Literal[:0]
 	t.Line = 0
 	t.Column = 0
+	t.Offset = 0
+	t.End = 0
+	t.Unit = nil
+	t.Flag = NoFlag
+	t.SpaceBefore = false
 }
 
 func NewToken() *Token {
@@ -74,6 +127,11 @@ func (t *Token) Reset() {
 	t.Literal = make([]byte, 0)
 	t.Line = 0
 	t.Column = 0
+	t.Offset = 0
+	t.End = 0
+	t.Unit = nil
+	t.Flag = NoFlag
+	t.SpaceBefore = false
 }
 
 var keywords = map[string]TokenType{}
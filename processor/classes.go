@@ -0,0 +1,58 @@
+package processor
+
+import (
+	"sort"
+
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+)
+
+// collectClasses returns the sorted, unique class names used by the selectors in the stylesheet.
+func collectClasses(stylesheet *parser.Stylesheet) []string {
+	seen := make(map[string]bool)
+	collectClassesFromRules(stylesheet.Rules, seen)
+
+	classes := make([]string, 0, len(seen))
+	for class := range seen {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+func collectClassesFromRules(rules []parser.Node, seen map[string]bool) {
+	for _, rule := range rules {
+		switch r := rule.(type) {
+		case *parser.Selector:
+			collectClassesFromSelectors(r.Selectors, seen)
+			collectClassesFromRules(r.Rules, seen)
+		case *parser.MediaAtRule:
+			collectClassesFromRules(r.Rules, seen)
+		case *parser.SupportsAtRule:
+			collectClassesFromRules(r.Rules, seen)
+		case *parser.LayerAtRule:
+			collectClassesFromRules(r.Rules, seen)
+		case *parser.ContainerAtRule:
+			collectClassesFromRules(r.Declarations, seen)
+		}
+	}
+}
+
+// collectClassesFromSelectors adds the class names of the selectors, including those in the
+// arguments of pseudo-classes such as :not(.a) and :nth-child(2n of .b).
+func collectClassesFromSelectors(list parser.SelectorList, seen map[string]bool) {
+	for _, complex := range list {
+		for _, compound := range complex.Compounds {
+			for _, simple := range compound.Selectors {
+				switch s := simple.(type) {
+				case *parser.ClassSelector:
+					seen[string(s.Name)] = true
+				case *parser.PseudoSelector:
+					collectClassesFromSelectors(s.Selectors, seen)
+					if s.Nth != nil {
+						collectClassesFromSelectors(s.Nth.Of, seen)
+					}
+				}
+			}
+		}
+	}
+}
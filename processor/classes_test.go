@@ -0,0 +1,39 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCollectClasses(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "Selectors", input: ".b, a.a:hover { color: red; }", expected: []string{"a", "b"}},
+		{name: "Media", input: "@media print { .a { color: red; } }", expected: []string{"a"}},
+		{name: "Supports", input: "@supports (display: grid) { .a { color: red; } }", expected: []string{"a"}},
+		{name: "Layer", input: "@layer base { .a { color: red; } }", expected: []string{"a"}},
+		{name: "Container", input: "@container (min-width: 400px) { .a { color: red; } }", expected: []string{"a"}},
+		{name: "Logical pseudo-classes", input: ":is(.a, p):not(.b):where(.c):has(> .d) { color: red; }", expected: []string{"a", "b", "c", "d"}},
+		{name: "Nth-child selector list", input: "li:nth-child(2n of .a, .b) { color: red; }", expected: []string{"a", "b"}},
+		{name: "Nested rules", input: ".a { color: red; & .b { color: red; } @media print { .c { color: red; } } }", expected: []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stylesheet, errors := parser.Parse(lexer.Lex(strings.NewReader(tt.input)))
+			if len(errors) > 0 {
+				t.Fatalf("Unexpected errors: %v", errors)
+			}
+			if diff := cmp.Diff(tt.expected, collectClasses(stylesheet)); diff != "" {
+				t.Errorf("Classes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aledsdavies/pristinecss"
+)
+
+// resolveCompositions flattens the composes declarations of each class into CSSClass.Composes.
+// Composed classes are listed in declaration order, followed by the classes they compose in turn.
+//
+// Parameters:
+// - result: The processing result whose classes are updated.
+// - compositions: The composes declarations keyed by the class path of the composing class.
+//
+// Returns:
+// - An error if a composed class does not exist or classes compose each other in a cycle.
+func resolveCompositions(result *Result, compositions map[string][]composition) error {
+	resolved := make(map[string][]pristinecss.CSSClass)
+
+	var resolve func(key string, chain []string) ([]pristinecss.CSSClass, error)
+	resolve = func(key string, chain []string) ([]pristinecss.CSSClass, error) {
+		if composed, ok := resolved[key]; ok {
+			return composed, nil
+		}
+		for _, seen := range chain {
+			if seen == key {
+				return nil, fmt.Errorf("composes cycle: %s", strings.Join(append(chain, key), " -> "))
+			}
+		}
+		chain = append(chain, key)
+
+		var composed []pristinecss.CSSClass
+		add := func(class pristinecss.CSSClass) {
+			for _, existing := range composed {
+				if existing.Path == class.Path && existing.Class == class.Class {
+					return
+				}
+			}
+			composed = append(composed, pristinecss.CSSClass{Path: class.Path, Class: class.Class})
+		}
+
+		for _, comp := range compositions[key] {
+			for _, name := range comp.Names {
+				if comp.Global {
+					add(pristinecss.CSSClass{Class: name})
+					continue
+				}
+
+				target := classPathPrefix(comp.From) + "." + name
+				class, ok := result.Classes[target]
+				if !ok {
+					return nil, fmt.Errorf("%s composes unknown class %s", key, target)
+				}
+				add(class)
+
+				nested, err := resolve(target, chain)
+				if err != nil {
+					return nil, err
+				}
+				for _, n := range nested {
+					add(n)
+				}
+			}
+		}
+
+		resolved[key] = composed
+		return composed, nil
+	}
+
+	for key := range compositions {
+		composed, err := resolve(key, nil)
+		if err != nil {
+			return err
+		}
+		class := result.Classes[key]
+		class.Composes = composed
+		result.Classes[key] = class
+	}
+
+	return nil
+}
@@ -0,0 +1,387 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+// scopeHashLength is the number of hex characters of the file hash appended to scoped class names
+const scopeHashLength = 6
+
+// ruleListAtRules are the at-rules whose blocks contain rules rather than declarations.
+var ruleListAtRules = map[string]bool{
+	"media":               true,
+	"supports":            true,
+	"container":           true,
+	"layer":               true,
+	"document":            true,
+	"-moz-document":       true,
+	"scope":               true,
+	"keyframes":           true,
+	"-webkit-keyframes":   true,
+	"starting-style":      true,
+	"font-feature-values": true,
+}
+
+// edit replaces the bytes between start and end of the source.
+type edit struct {
+	start       int
+	end         int
+	replacement []byte
+}
+
+// moduleFile is the result of scoping a single file.
+type moduleFile struct {
+	// Content is the rewritten CSS
+	Content []byte
+	// Classes maps each local class name to its scoped name
+	Classes map[string]string
+	// Composes maps each local class name to the classes it composes, in declaration order
+	Composes map[string][]composition
+
+	// edits are the edits that turned the source into Content, sorted by start
+	edits []edit
+}
+
+// composition is a single composes declaration.
+type composition struct {
+	// Names are the class names being composed
+	Names []string
+	// From is the relative filepath the names are defined in, which is the composing file unless
+	// a from clause is given
+	From string
+	// Global is true for composes: <names> from global
+	Global bool
+}
+
+// scopeClasses rewrites every local class selector in the content to a name scoped to the file,
+// following the CSS Modules conventions. Classes inside :global(...), or following a bare :global,
+// are left untouched; :local switches back to scoping. composes declarations are recorded and
+// removed from the output.
+//
+// Parameters:
+// - content: The CSS source of the file.
+// - relPath: The relative filepath of the file, used to derive the scope and resolve composes.
+//
+// Returns:
+// - The scoped file.
+// - An error if a composes declaration is malformed or used outside a single class selector.
+func scopeClasses(content []byte, relPath string) (*moduleFile, error) {
+	s := &scoper{
+		content:  content,
+		relPath:  relPath,
+		toks:     lexer.LexBytes(content),
+		suffix:   "_" + contentHash([]byte(relPath))[:scopeHashLength],
+		classes:  make(map[string]string),
+		composes: make(map[string][]composition),
+	}
+	if err := s.scope(); err != nil {
+		return nil, err
+	}
+	return &moduleFile{
+		Content:  s.apply(),
+		Classes:  s.classes,
+		Composes: s.composes,
+		edits:    s.edits,
+	}, nil
+}
+
+type scoper struct {
+	content  []byte
+	relPath  string
+	toks     []tokens.Token
+	pos      int
+	suffix   string
+	edits    []edit
+	classes  map[string]string
+	composes map[string][]composition
+}
+
+// block is an open '{' block.
+type block struct {
+	// rules is true if the block contains rules rather than declarations
+	rules bool
+	// owner is the local class of a style rule whose selector is a single class, which is the
+	// only place composes is allowed
+	owner string
+}
+
+// scope walks the token stream, tracking whether each block holds rules or declarations so
+// that only selector preludes are rewritten. Style rules nested in a declaration block, as in
+// .card { & .title { } }, are scoped like those at the top level.
+func (s *scoper) scope() error {
+	blocks := []block{{rules: true}}
+	// itemStart is set when the current token can start a declaration or nested rule
+	itemStart := true
+
+	for !s.currentIs(tokens.EOF) {
+		current := blocks[len(blocks)-1]
+
+		switch {
+		case s.currentIs(tokens.RBRACE):
+			if len(blocks) > 1 {
+				blocks = blocks[:len(blocks)-1]
+			}
+			s.pos++
+			itemStart = true
+		case s.currentIs(tokens.LBRACE):
+			blocks = append(blocks, block{rules: current.rules})
+			s.pos++
+			itemStart = true
+		case !current.rules && s.currentIs(tokens.IDENT) && string(s.toks[s.pos].Literal) == "composes" && s.nextIs(tokens.COLON):
+			if err := s.parseComposes(current.owner); err != nil {
+				return err
+			}
+			itemStart = true
+		case !current.rules && itemStart && s.startsNestedRule():
+			owner := s.scopeSelectorPrelude()
+			if s.currentIs(tokens.LBRACE) {
+				blocks = append(blocks, block{owner: owner})
+				s.pos++
+			}
+		case s.currentIs(tokens.COMMENT):
+			s.pos++
+		case !current.rules:
+			itemStart = s.currentIs(tokens.SEMICOLON)
+			s.pos++
+		case s.currentIs(tokens.AT):
+			s.pos++ // Consume the @
+			name := string(s.toks[s.pos].Literal)
+			for !s.currentIs(tokens.LBRACE) && !s.currentIs(tokens.SEMICOLON) && !s.currentIs(tokens.EOF) {
+				s.pos++
+			}
+			if s.currentIs(tokens.LBRACE) {
+				blocks = append(blocks, block{rules: ruleListAtRules[name]})
+			}
+			if !s.currentIs(tokens.EOF) {
+				s.pos++ // Consume the '{' or ';'
+			}
+		default:
+			owner := s.scopeSelectorPrelude()
+			if s.currentIs(tokens.LBRACE) {
+				blocks = append(blocks, block{owner: owner})
+				s.pos++
+			}
+		}
+	}
+
+	return nil
+}
+
+// startsNestedRule reports whether the item of a declaration block that starts at the current
+// token is a nested style rule rather than a declaration or at-rule, by whether a '{' comes
+// before the ';' or '}' that would end a declaration.
+func (s *scoper) startsNestedRule() bool {
+	tok := s.toks[s.pos]
+	if tok.Type == tokens.AT || (tok.Type == tokens.IDENT && bytes.HasPrefix(tok.Literal, []byte("--"))) {
+		return false
+	}
+	depth := 0
+	for i := s.pos; i < len(s.toks); i++ {
+		switch s.toks[i].Type {
+		case tokens.LPAREN, tokens.LBRACKET:
+			depth++
+		case tokens.RPAREN, tokens.RBRACKET:
+			depth--
+		case tokens.LBRACE:
+			return depth <= 0
+		case tokens.SEMICOLON, tokens.RBRACE:
+			if depth <= 0 {
+				return false
+			}
+		case tokens.EOF:
+			return false
+		}
+	}
+	return false
+}
+
+// parseComposes records a composes declaration for the owning class and removes it from the output.
+func (s *scoper) parseComposes(owner string) error {
+	start := s.toks[s.pos]
+	if owner == "" {
+		return fmt.Errorf("%s:%d:%d: composes is only allowed in a rule with a single local class selector",
+			s.relPath, start.Line, start.Column)
+	}
+	s.pos += 2 // Consume 'composes' and ':'
+
+	comp := composition{From: s.relPath}
+	for s.currentIs(tokens.IDENT) && string(s.toks[s.pos].Literal) != "from" {
+		comp.Names = append(comp.Names, string(s.toks[s.pos].Literal))
+		s.pos++
+	}
+	if s.currentIs(tokens.IDENT) {
+		s.pos++ // Consume 'from'
+		switch {
+		case s.currentIs(tokens.IDENT) && string(s.toks[s.pos].Literal) == "global":
+			comp.From = ""
+			comp.Global = true
+		case s.currentIs(tokens.STRING):
+			literal := s.toks[s.pos].Literal
+			comp.From = path.Join(path.Dir(s.relPath), string(literal[1:len(literal)-1]))
+		default:
+			tok := s.toks[s.pos]
+			return fmt.Errorf("%s:%d:%d: expected a string or global after from in composes",
+				s.relPath, tok.Line, tok.Column)
+		}
+		s.pos++
+	}
+
+	if len(comp.Names) == 0 || (!s.currentIs(tokens.SEMICOLON) && !s.currentIs(tokens.RBRACE)) {
+		tok := s.toks[s.pos]
+		return fmt.Errorf("%s:%d:%d: malformed composes declaration", s.relPath, tok.Line, tok.Column)
+	}
+
+	end := s.toks[s.pos].Offset
+	if s.currentIs(tokens.SEMICOLON) {
+		s.pos++ // Consume ';'
+		end = s.toks[s.pos].Offset
+		if s.currentIs(tokens.EOF) {
+			end = len(s.content)
+		}
+	}
+	s.remove(start.Offset, end)
+	s.composes[owner] = append(s.composes[owner], comp)
+
+	return nil
+}
+
+// scopeSelectorPrelude rewrites the class selectors up to the '{' that opens the rule's block.
+// It returns the local class name if the selector is a single local class.
+func (s *scoper) scopeSelectorPrelude() string {
+	start := s.pos
+	owner := ""
+	global := false
+	// Records, for each open parenthesis, the mode to restore when it closes and whether it
+	// belongs to :global(...) or :local(...) and must be removed
+	type paren struct {
+		restore bool
+		remove  bool
+	}
+	var parens []paren
+
+	for !s.currentIs(tokens.LBRACE) && !s.currentIs(tokens.RBRACE) && !s.currentIs(tokens.EOF) {
+		tok := s.toks[s.pos]
+
+		switch {
+		case tok.Type == tokens.COLON && s.nextIs(tokens.IDENT) && isScopeKeyword(s.toks[s.pos+1].Literal):
+			keyword := s.toks[s.pos+1]
+			if s.peekIs(2, tokens.LPAREN) {
+				// Functional form, :global(.a) or :local(.a)
+				s.remove(tok.Offset, s.toks[s.pos+2].End)
+				parens = append(parens, paren{restore: global, remove: true})
+				global = string(keyword.Literal) == "global"
+				s.pos += 3
+				continue
+			}
+			// Bare form, :global .a, which applies to the rest of the selector
+			end := keyword.End
+			if s.pos+2 < len(s.toks) {
+				next := s.toks[s.pos+2]
+				if next.Type != tokens.LBRACE && next.Type != tokens.COMMA && next.Type != tokens.EOF {
+					end = next.Offset
+				}
+			}
+			s.remove(tok.Offset, end)
+			global = string(keyword.Literal) == "global"
+			s.pos += 2
+			continue
+		case tok.Type == tokens.LPAREN:
+			parens = append(parens, paren{restore: global})
+		case tok.Type == tokens.RPAREN && len(parens) > 0:
+			p := parens[len(parens)-1]
+			parens = parens[:len(parens)-1]
+			if p.remove {
+				s.remove(tok.Offset, tok.End)
+			}
+			global = p.restore
+		case tok.Type == tokens.COMMA && len(parens) == 0:
+			global = false
+		case tok.Type == tokens.DOT && s.nextIs(tokens.IDENT):
+			ident := s.toks[s.pos+1]
+			if ident.Offset == tok.End && !global {
+				s.scopeClass(ident)
+				if s.pos == start && s.peekIs(2, tokens.LBRACE) {
+					owner = string(ident.Literal)
+				}
+			}
+			s.pos += 2
+			continue
+		}
+		s.pos++
+	}
+
+	return owner
+}
+
+// scopeClass replaces the class identifier with its scoped name.
+func (s *scoper) scopeClass(ident tokens.Token) {
+	local := string(ident.Literal)
+	scoped, ok := s.classes[local]
+	if !ok {
+		scoped = local + s.suffix
+		s.classes[local] = scoped
+	}
+	s.edits = append(s.edits, edit{
+		start:       ident.Offset,
+		end:         ident.End,
+		replacement: []byte(scoped),
+	})
+}
+
+func (s *scoper) remove(start, end int) {
+	s.edits = append(s.edits, edit{start: start, end: end})
+}
+
+// apply returns the content with all edits applied.
+func (s *scoper) apply() []byte {
+	sort.SliceStable(s.edits, func(i, j int) bool {
+		return s.edits[i].start < s.edits[j].start
+	})
+
+	var out bytes.Buffer
+	out.Grow(len(s.content))
+	last := 0
+	for _, e := range s.edits {
+		out.Write(s.content[last:e.start])
+		out.Write(e.replacement)
+		last = e.end
+	}
+	out.Write(s.content[last:])
+	return out.Bytes()
+}
+
+func (s *scoper) currentIs(tokenType tokens.TokenType) bool {
+	return s.peekIs(0, tokenType)
+}
+
+func (s *scoper) nextIs(tokenType tokens.TokenType) bool {
+	return s.peekIs(1, tokenType)
+}
+
+func (s *scoper) peekIs(n int, tokenType tokens.TokenType) bool {
+	if s.pos+n >= len(s.toks) {
+		return tokenType == tokens.EOF
+	}
+	return s.toks[s.pos+n].Type == tokenType
+}
+
+func isScopeKeyword(literal []byte) bool {
+	return string(literal) == "global" || string(literal) == "local"
+}
+
+// lineStarts returns the byte offset at which each line of the content begins.
+func lineStarts(content []byte) []int {
+	starts := []int{0}
+	for i, ch := range content {
+		if ch == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
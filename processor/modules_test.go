@@ -0,0 +1,133 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScopeClasses(t *testing.T) {
+	suffix := "_" + contentHash([]byte("main.css"))[:scopeHashLength]
+
+	tests := []struct {
+		name            string
+		input           string
+		expected        string
+		expectedClasses map[string]string
+	}{
+		{
+			name:            "Class selectors",
+			input:           ".button.primary, div > .button { color: blue; }",
+			expected:        ".button" + suffix + ".primary" + suffix + ", div > .button" + suffix + " { color: blue; }",
+			expectedClasses: map[string]string{"button": "button" + suffix, "primary": "primary" + suffix},
+		},
+		{
+			name:            "Functional global",
+			input:           ":global(.title) .button:not(.active) { margin: 0; }",
+			expected:        ".title .button" + suffix + ":not(.active" + suffix + ") { margin: 0; }",
+			expectedClasses: map[string]string{"button": "button" + suffix, "active": "active" + suffix},
+		},
+		{
+			name:            "Bare global and local",
+			input:           ".a :global .b :local .c, .d { margin: 0; }",
+			expected:        ".a" + suffix + " .b .c" + suffix + ", .d" + suffix + " { margin: 0; }",
+			expectedClasses: map[string]string{"a": "a" + suffix, "c": "c" + suffix, "d": "d" + suffix},
+		},
+		{
+			name:            "Local inside global",
+			input:           ":global(.theme :local(.card)) { margin: 0; }",
+			expected:        ".theme .card" + suffix + " { margin: 0; }",
+			expectedClasses: map[string]string{"card": "card" + suffix},
+		},
+		{
+			name: "Nested at-rules and declarations",
+			input: `@import url("base.css");
+@media (min-width: 768px) {
+  .card { background: url(img.png); width: .5em; }
+}
+@font-face { font-family: x; }`,
+			expected: `@import url("base.css");
+@media (min-width: 768px) {
+  .card` + suffix + ` { background: url(img.png); width: .5em; }
+}
+@font-face { font-family: x; }`,
+			expectedClasses: map[string]string{"card": "card" + suffix},
+		},
+		{
+			name:            "Nested style rules",
+			input:           ".card { color: red; .title { margin: 0; } & .body:not(.x) { padding: 0; } @media print { > .footer { color: blue; } } a:hover { color: red; } }",
+			expected:        ".card" + suffix + " { color: red; .title" + suffix + " { margin: 0; } & .body" + suffix + ":not(.x" + suffix + ") { padding: 0; } @media print { > .footer" + suffix + " { color: blue; } } a:hover { color: red; } }",
+			expectedClasses: map[string]string{"card": "card" + suffix, "title": "title" + suffix, "body": "body" + suffix, "x": "x" + suffix, "footer": "footer" + suffix},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module, err := scopeClasses([]byte(tt.input), "main.css")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, string(module.Content)); diff != "" {
+				t.Errorf("Output mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expectedClasses, module.Classes); diff != "" {
+				t.Errorf("Classes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScopeClassesComposes(t *testing.T) {
+	suffix := "_" + contentHash([]byte("components/button.css"))[:scopeHashLength]
+
+	input := `.base { padding: 0; }
+.button {
+  composes: base;
+  composes: reset from "../shared.css";
+  composes: clearfix from global;
+  color: blue;
+}`
+	expected := `.base` + suffix + ` { padding: 0; }
+.button` + suffix + ` {
+  color: blue;
+}`
+
+	module, err := scopeClasses([]byte(input), "components/button.css")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, string(module.Content)); diff != "" {
+		t.Errorf("Output mismatch (-want +got):\n%s", diff)
+	}
+
+	expectedComposes := map[string][]composition{
+		"button": {
+			{Names: []string{"base"}, From: "components/button.css"},
+			{Names: []string{"reset"}, From: "shared.css"},
+			{Names: []string{"clearfix"}, Global: true},
+		},
+	}
+	if diff := cmp.Diff(expectedComposes, module.Composes, cmp.AllowUnexported(composition{})); diff != "" {
+		t.Errorf("Composes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestScopeClassesComposesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Compound selector", ".a.b { composes: c; }"},
+		{"Element selector", "div { composes: c; }"},
+		{"Missing names", ".a { composes: ; }"},
+		{"Bad from", ".a { composes: b from c; }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := scopeClasses([]byte(tt.input), "main.css"); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
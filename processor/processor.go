@@ -1,18 +1,32 @@
 package processor
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aledsdavies/pristinecss"
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/parser"
+	"github.com/aledsdavies/pristinecss/pkg/sourcemap"
 )
 
 type ProcessorOpt func(*processorOptions)
 
 type processorOptions struct {
-	verbose   bool
-	fileType  string
-	outputDir string
-	stylesDir string
+	verbose       bool
+	fileType      string
+	outputDir     string
+	stylesDir     string
+	scopedClasses bool
+	sourceMaps    SourceMapMode
 }
 
 func WithVerbose(verbose bool) ProcessorOpt {
@@ -41,9 +55,47 @@ func WithStylesDir(stylesDir string) ProcessorOpt {
 	}
 }
 
+// WithScopedClasses rewrites the class names in each file to names scoped to that file, in the
+// style of CSS Modules, so that classes with the same name in different files do not collide.
+// composes declarations are resolved into CSSClass.Composes and removed from the output.
+func WithScopedClasses(scoped bool) ProcessorOpt {
+	return func(opts *processorOptions) {
+		opts.scopedClasses = scoped
+	}
+}
+
+// WithSourceMaps writes a source map for each processed file that maps the versioned copy back to
+// the original file, so that devtools still point at the original line after classes are scoped.
+func WithSourceMaps(mode SourceMapMode) ProcessorOpt {
+	return func(opts *processorOptions) {
+		opts.sourceMaps = mode
+	}
+}
+
 type ProcessFunction func(reader io.Reader) ProcessFunction
 
-func Process(reader io.Reader, opts ...ProcessorOpt) {
+// hashLength is the number of hex characters of the content hash used to version a file
+const hashLength = 10
+
+// Result holds the output of a processing run in the shape consumed by pristinecss.NewLoader.
+type Result struct {
+	// Files maps the relative filepath of each source file to its versioned path
+	Files map[string]string
+	// Classes maps each class path (<filepath>.<classname>) to its class
+	Classes map[string]pristinecss.CSSClass
+}
+
+// Process walks the source directory, parses every style file and writes a versioned copy of
+// each one into the styles directory.
+//
+// Parameters:
+// - source: The directory containing the style files to process.
+// - opts: Options to configure the processor.
+//
+// Returns:
+// - The versioned files and collected classes.
+// - An error if a file could not be read or written.
+func Process(source string, opts ...ProcessorOpt) (*Result, error) {
 	options := &processorOptions{
 		fileType:  ".css",
 		outputDir: "./public",
@@ -56,18 +108,178 @@ func Process(reader io.Reader, opts ...ProcessorOpt) {
 
 	err := os.MkdirAll(options.stylesDir, 0755)
 	if err != nil {
-		log.Fatalf("Failed to create directory: %s, error: %v", options.stylesDir, err)
-		return
+		return nil, fmt.Errorf("failed to create directory: %s, error: %w", options.stylesDir, err)
+	}
+
+	skipDir, err := stylesDirRel(source, options.stylesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Files:   make(map[string]string),
+		Classes: make(map[string]pristinecss.CSSClass),
+	}
+
+	// Composes declarations can refer to files that have not been processed yet, so they are
+	// resolved once every file has been walked
+	compositions := make(map[string][]composition)
+
+	fsys := os.DirFS(source)
+	err = fs.WalkDir(fsys, ".", func(relPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if relPath == skipDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(relPath) != options.fileType {
+			return nil
+		}
+		return processFile(fsys, relPath, options, result, compositions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolveCompositions(result, compositions); err != nil {
+		return nil, err
+	}
+
+	if options.verbose {
+		log.Printf("Finished processing files in directory: %s", source)
+	}
+
+	return result, nil
+}
+
+// processFile parses a single style file, records its classes and writes the versioned copy.
+// The composes declarations of the file are added to compositions, keyed by class path.
+func processFile(fsys fs.FS, relPath string, options *processorOptions, result *Result, compositions map[string][]composition) error {
+	content, err := fs.ReadFile(fsys, relPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %s, error: %w", relPath, err)
+	}
+
+	stylesheet, parseErrors := parser.Parse(lexer.LexBytes(content), parser.WithSource(relPath))
+	for _, parseErr := range parseErrors {
+		log.Printf("%s: %s", relPath, strings.TrimSpace(parseErr.Error()))
+	}
+
+	original := content
+	var edits []edit
+
+	classPrefix := classPathPrefix(relPath)
+	classes := make(map[string]string)
+	if options.scopedClasses {
+		module, err := scopeClasses(content, relPath)
+		if err != nil {
+			return err
+		}
+		content, classes, edits = module.Content, module.Classes, module.edits
+		for local, comps := range module.Composes {
+			compositions[classPrefix+"."+local] = comps
+		}
+	} else {
+		for _, class := range collectClasses(stylesheet) {
+			classes[class] = class
+		}
+	}
+
+	var sourceMap []byte
+	if options.sourceMaps != NoSourceMap {
+		g := buildSourceMap(relPath, original, content, edits)
+		if sourceMap, err = g.MarshalJSON(); err != nil {
+			return fmt.Errorf("failed to encode source map: %s, error: %w", relPath, err)
+		}
+
+		// The link only depends on the file name, so it is added before hashing
+		switch options.sourceMaps {
+		case LinkedSourceMap:
+			content = appendSourceMapURL(content, path.Base(relPath)+".map")
+		case InlineSourceMap:
+			url, err := g.DataURL()
+			if err != nil {
+				return fmt.Errorf("failed to encode source map: %s, error: %w", relPath, err)
+			}
+			content = appendSourceMapURL(content, url)
+			sourceMap = nil
+		}
+	}
+
+	hash := contentHash(content)
+	versionedPath := path.Join("/", hash, relPath)
+
+	outPath := filepath.Join(options.stylesDir, hash, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %s, error: %w", filepath.Dir(outPath), err)
+	}
+	if err := os.WriteFile(outPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %s, error: %w", outPath, err)
+	}
+	if sourceMap != nil {
+		if err := os.WriteFile(outPath+".map", sourceMap, 0644); err != nil {
+			return fmt.Errorf("failed to write file: %s, error: %w", outPath+".map", err)
+		}
 	}
 
-	// processing logic here
-	// process the file with processor (css, scss, tailwind, postcss)
-	// take a hash of the files contents
-	// add to the this to a list of relative filepath => hash
-	// add list of available css classes to classpath => CSSClass
-	// write modified content to reletve path in output folder
+	result.Files[relPath] = versionedPath
 
-	log.Printf("Finished processing files in directory: %s", source)
+	for local, class := range classes {
+		result.Classes[classPrefix+"."+local] = pristinecss.CSSClass{
+			Path:  relPath,
+			Class: class,
+		}
+	}
+
+	if options.verbose {
+		log.Printf("Processed %s -> %s", relPath, versionedPath)
+	}
+
+	return nil
+}
+
+// appendSourceMapURL appends a sourceMappingURL comment on its own line to the content.
+func appendSourceMapURL(content []byte, url string) []byte {
+	out := append([]byte{}, content...)
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	return append(out, sourcemap.URLComment(url)...)
+}
+
+// classPathPrefix returns the prefix of the class paths for classes defined in the file.
+func classPathPrefix(relPath string) string {
+	return strings.TrimSuffix(relPath, path.Ext(relPath))
+}
+
+// contentHash returns the truncated hex encoded md5 hash of the content.
+func contentHash(content []byte) string {
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// stylesDirRel returns the styles directory relative to the source so that previously
+// generated output is not processed again. It returns an empty string when the styles
+// directory is outside the source.
+func stylesDirRel(source, stylesDir string) (string, error) {
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return "", err
+	}
+	absStyles, err := filepath.Abs(stylesDir)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(absSource, absStyles)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
 }
 
 // createDirIfNotExists creates a directory only if it does not already exist.
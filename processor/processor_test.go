@@ -0,0 +1,279 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aledsdavies/pristinecss"
+	"github.com/aledsdavies/pristinecss/pkg/sourcemap"
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Could not create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Could not write %s: %v", name, err)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	source := t.TempDir()
+	stylesDir := filepath.Join(t.TempDir(), "styles")
+
+	writeFiles(t, source, map[string]string{
+		"main.css":              ".button { color: blue; }\n.header, .button:hover { margin: 0; }",
+		"components/card.css":   "@media (min-width: 768px) { .card { padding: 10px; } }",
+		"reset.css":             "body { margin: 0; }",
+		"components/readme.txt": ".ignored { color: red; }",
+	})
+
+	result, err := Process(source, WithStylesDir(stylesDir))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedFiles := map[string]string{
+		"main.css":            "/" + contentHash([]byte(".button { color: blue; }\n.header, .button:hover { margin: 0; }")) + "/main.css",
+		"components/card.css": "/" + contentHash([]byte("@media (min-width: 768px) { .card { padding: 10px; } }")) + "/components/card.css",
+		"reset.css":           "/" + contentHash([]byte("body { margin: 0; }")) + "/reset.css",
+	}
+	if diff := cmp.Diff(expectedFiles, result.Files); diff != "" {
+		t.Errorf("Files mismatch (-want +got):\n%s", diff)
+	}
+
+	expectedClasses := map[string]pristinecss.CSSClass{
+		"main.button":          {Path: "main.css", Class: "button"},
+		"main.header":          {Path: "main.css", Class: "header"},
+		"components/card.card": {Path: "components/card.css", Class: "card"},
+	}
+	if diff := cmp.Diff(expectedClasses, result.Classes); diff != "" {
+		t.Errorf("Classes mismatch (-want +got):\n%s", diff)
+	}
+
+	for name, versioned := range result.Files {
+		written, err := os.ReadFile(filepath.Join(stylesDir, filepath.FromSlash(versioned)))
+		if err != nil {
+			t.Errorf("Versioned copy of %s was not written: %v", name, err)
+			continue
+		}
+		original, _ := os.ReadFile(filepath.Join(source, filepath.FromSlash(name)))
+		if string(written) != string(original) {
+			t.Errorf("Versioned copy of %s does not match the source", name)
+		}
+	}
+}
+
+func TestProcessSkipsStylesDir(t *testing.T) {
+	source := t.TempDir()
+	stylesDir := filepath.Join(source, "public", "styles")
+
+	writeFiles(t, source, map[string]string{
+		"main.css":                    ".button { color: blue; }",
+		"public/styles/old/stale.css": ".stale { color: red; }",
+	})
+
+	result, err := Process(source, WithStylesDir(stylesDir))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, ok := result.Files["public/styles/old/stale.css"]; ok {
+		t.Errorf("Expected files in the styles directory to be skipped")
+	}
+	if _, ok := result.Files["main.css"]; !ok {
+		t.Errorf("Expected main.css to be processed")
+	}
+}
+
+func TestProcessScopedClasses(t *testing.T) {
+	source := t.TempDir()
+	stylesDir := filepath.Join(t.TempDir(), "styles")
+
+	writeFiles(t, source, map[string]string{
+		"main.css":   ".button { color: blue; }",
+		"header.css": ".button { color: red; }",
+	})
+
+	result, err := Process(source, WithStylesDir(stylesDir), WithScopedClasses(true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mainClass := result.Classes["main.button"]
+	headerClass := result.Classes["header.button"]
+	if mainClass.Class == headerClass.Class {
+		t.Errorf("Expected classes in different files to be scoped apart, both were %q", mainClass.Class)
+	}
+
+	written, err := os.ReadFile(filepath.Join(stylesDir, filepath.FromSlash(result.Files["main.css"])))
+	if err != nil {
+		t.Fatalf("Versioned copy of main.css was not written: %v", err)
+	}
+	if expected := "." + mainClass.Class + " { color: blue; }"; string(written) != expected {
+		t.Errorf("Expected written file %q, got %q", expected, written)
+	}
+}
+
+func TestProcessComposes(t *testing.T) {
+	source := t.TempDir()
+	stylesDir := filepath.Join(t.TempDir(), "styles")
+
+	writeFiles(t, source, map[string]string{
+		"shared.css": ".base { padding: 0; }\n.reset { margin: 0; composes: base; }",
+		"main.css":   ".button { composes: reset from \"./shared.css\"; composes: clearfix from global; color: blue; }",
+	})
+
+	result, err := Process(source, WithStylesDir(stylesDir), WithScopedClasses(true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	base := result.Classes["shared.base"]
+	reset := result.Classes["shared.reset"]
+	expected := []pristinecss.CSSClass{
+		{Path: "shared.css", Class: reset.Class},
+		{Path: "shared.css", Class: base.Class},
+		{Class: "clearfix"},
+	}
+	if diff := cmp.Diff(expected, result.Classes["main.button"].Composes); diff != "" {
+		t.Errorf("Composes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessComposesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name:  "Unknown class",
+			files: map[string]string{"main.css": ".a { composes: missing; }"},
+		},
+		{
+			name: "Cycle",
+			files: map[string]string{
+				"main.css":  ".a { composes: b from \"./other.css\"; }",
+				"other.css": ".b { composes: a from \"./main.css\"; }",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := t.TempDir()
+			writeFiles(t, source, tt.files)
+
+			_, err := Process(source, WithStylesDir(filepath.Join(t.TempDir(), "styles")), WithScopedClasses(true))
+			if err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestProcessSourceMaps(t *testing.T) {
+	source := t.TempDir()
+	stylesDir := filepath.Join(t.TempDir(), "styles")
+
+	writeFiles(t, source, map[string]string{
+		"main.css":              ".button {\n  composes: base;\n  color: blue;\n}\n.base { margin: 0; }",
+		"components/button.css": ".button { color: red; }",
+	})
+
+	result, err := Process(source, WithStylesDir(stylesDir), WithScopedClasses(true), WithSourceMaps(LinkedSourceMap))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	outPath := filepath.Join(stylesDir, filepath.FromSlash(result.Files["main.css"]))
+	written, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("Versioned copy of main.css was not written: %v", err)
+	}
+	if !strings.HasSuffix(string(written), "\n/*# sourceMappingURL=main.css.map */") {
+		t.Errorf("Expected the versioned copy to link its source map, got %q", written)
+	}
+
+	data, err := os.ReadFile(outPath + ".map")
+	if err != nil {
+		t.Fatalf("Source map was not written: %v", err)
+	}
+	var sourceMap struct {
+		Version        int      `json:"version"`
+		Sources        []string `json:"sources"`
+		SourcesContent []string `json:"sourcesContent"`
+	}
+	if err := json.Unmarshal(data, &sourceMap); err != nil {
+		t.Fatalf("Could not decode source map: %v", err)
+	}
+	if sourceMap.Version != 3 || !cmp.Equal([]string{"pristine:///main.css"}, sourceMap.Sources) {
+		t.Errorf("Unexpected source map %s", data)
+	}
+
+	// The source of a nested file's map must not resolve to the versioned copy the map sits next to
+	nestedPath := filepath.Join(stylesDir, filepath.FromSlash(result.Files["components/button.css"]))
+	data, err = os.ReadFile(nestedPath + ".map")
+	if err != nil {
+		t.Fatalf("Source map of components/button.css was not written: %v", err)
+	}
+	if err := json.Unmarshal(data, &sourceMap); err != nil {
+		t.Fatalf("Could not decode source map: %v", err)
+	}
+	if len(sourceMap.Sources) != 1 {
+		t.Fatalf("Expected one source, got %s", data)
+	}
+
+	cssURL, err := url.Parse("https://example.com/styles" + result.Files["components/button.css"])
+	if err != nil {
+		t.Fatalf("Could not parse the stylesheet URL: %v", err)
+	}
+	mapURL := cssURL.JoinPath("..", path.Base(cssURL.Path)+".map")
+	sourceURL, err := url.Parse(sourceMap.Sources[0])
+	if err != nil {
+		t.Fatalf("Could not parse the source %q: %v", sourceMap.Sources[0], err)
+	}
+	resolved := mapURL.ResolveReference(sourceURL)
+	if resolved.String() == cssURL.String() {
+		t.Errorf("Expected the source to resolve to a URL other than the stylesheet's, got %s", resolved)
+	}
+	if resolved.String() != "pristine:///components/button.css" {
+		t.Errorf("Expected the source to name the original file, got %s", resolved)
+	}
+}
+
+func TestBuildSourceMap(t *testing.T) {
+	original := []byte(".button {\n  composes: base;\n  color: blue;\n}")
+	module, err := scopeClasses(original, "main.css")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := buildSourceMap("main.css", original, module.Content, module.edits)
+
+	scoped := module.Classes["button"]
+	expected := []sourcemap.Mapping{
+		{GeneratedLine: 1, GeneratedColumn: 1, Source: "pristine:///main.css", OriginalLine: 1, OriginalColumn: 1},
+		{GeneratedLine: 1, GeneratedColumn: 2, Source: "pristine:///main.css", OriginalLine: 1, OriginalColumn: 2},
+		{GeneratedLine: 1, GeneratedColumn: len(scoped) + 3, Source: "pristine:///main.css", OriginalLine: 1, OriginalColumn: 9},
+		// The composes declaration is removed, so color moves up a line
+		{GeneratedLine: 2, GeneratedColumn: 3, Source: "pristine:///main.css", OriginalLine: 3, OriginalColumn: 3},
+		{GeneratedLine: 2, GeneratedColumn: 8, Source: "pristine:///main.css", OriginalLine: 3, OriginalColumn: 8},
+		{GeneratedLine: 2, GeneratedColumn: 10, Source: "pristine:///main.css", OriginalLine: 3, OriginalColumn: 10},
+		{GeneratedLine: 2, GeneratedColumn: 14, Source: "pristine:///main.css", OriginalLine: 3, OriginalColumn: 14},
+		{GeneratedLine: 3, GeneratedColumn: 1, Source: "pristine:///main.css", OriginalLine: 4, OriginalColumn: 1},
+	}
+	if diff := cmp.Diff(expected, g.Mappings()); diff != "" {
+		t.Errorf("Mappings mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"path"
+	"sort"
+
+	"github.com/aledsdavies/pristinecss/pkg/lexer"
+	"github.com/aledsdavies/pristinecss/pkg/sourcemap"
+	"github.com/aledsdavies/pristinecss/pkg/tokens"
+)
+
+// SourceMapMode controls whether and how source maps are written for processed files.
+type SourceMapMode int
+
+const (
+	// NoSourceMap writes no source maps
+	NoSourceMap SourceMapMode = iota
+	// LinkedSourceMap writes a .map file next to each processed file and links it with a
+	// sourceMappingURL comment
+	LinkedSourceMap
+	// ExternalSourceMap writes a .map file next to each processed file without linking it
+	ExternalSourceMap
+	// InlineSourceMap embeds the source map in a sourceMappingURL comment as a data URL
+	InlineSourceMap
+)
+
+// sourceScheme prefixes the relative filepath of a processed file to name the source of its map.
+// The versioned copy shares the file's name, so a relative name would resolve against the map to
+// the copy itself. The original content is embedded, so devtools show it under this name.
+const sourceScheme = "pristine:///"
+
+// buildSourceMap maps the start of every token of the original content that survives the edits
+// to its position in the output, so that devtools point at the original file.
+//
+// Parameters:
+// - relPath: The relative filepath of the file, used with sourceScheme as the name of the source.
+// - original: The content of the file before the edits.
+// - output: The content written for the file.
+// - edits: The edits that turned original into output, sorted by start.
+//
+// Returns:
+// - The source map, with the original content embedded.
+func buildSourceMap(relPath string, original, output []byte, edits []edit) *sourcemap.Generator {
+	name := sourceScheme + relPath
+	g := sourcemap.New(path.Base(relPath))
+	g.SetSourceContent(name, original)
+
+	outputStarts := lineStarts(output)
+
+	delta := 0
+	next := 0
+	for _, tok := range lexer.LexBytes(original) {
+		if tok.Type == tokens.EOF {
+			break
+		}
+		offset := tok.Offset
+
+		// Apply the edits that end at or before the token
+		for next < len(edits) && edits[next].end <= offset {
+			delta += len(edits[next].replacement) - (edits[next].end - edits[next].start)
+			next++
+		}
+		if next < len(edits) && edits[next].start <= offset && offset < edits[next].end &&
+			(edits[next].start != offset || len(edits[next].replacement) == 0) {
+			// The token was removed
+			continue
+		}
+
+		line, column := position(outputStarts, offset+delta)
+		g.AddMapping(sourcemap.Mapping{
+			GeneratedLine:   line,
+			GeneratedColumn: column,
+			Source:          name,
+			OriginalLine:    tok.Line,
+			OriginalColumn:  tok.Column,
+		})
+	}
+
+	return g
+}
+
+// position returns the 1-based line and column of the byte offset.
+func position(lineStarts []int, offset int) (int, int) {
+	line := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset })
+	return line, offset - lineStarts[line-1] + 1
+}